@@ -0,0 +1,39 @@
+package v1alpha1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RuleObject is implemented by Rule and ClusterRule, controllers handle both
+// kinds with it.
+type RuleObject interface {
+	client.Object
+	GetRuleSpec() *RuleSpec
+	GetRuleStatus() *RuleStatus
+	// Validate checks the spec the same way as the validating webhook.
+	Validate() error
+	// AsRule returns the object as a Rule, a ClusterRule is a Rule of empty
+	// namespace. It's a shallow copy for reading, changes are not written back.
+	AsRule() *Rule
+}
+
+var _ RuleObject = &Rule{}
+var _ RuleObject = &ClusterRule{}
+
+// RuleRef returns namespace/name of a Rule, and clusterrule/name of a ClusterRule.
+func RuleRef(obj RuleObject) string {
+	if obj.GetNamespace() == "" {
+		return "clusterrule/" + obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func (r *Rule) GetRuleSpec() *RuleSpec     { return &r.Spec }
+func (r *Rule) GetRuleStatus() *RuleStatus { return &r.Status }
+func (r *Rule) AsRule() *Rule              { return r }
+
+func (r *ClusterRule) GetRuleSpec() *RuleSpec     { return &r.Spec }
+func (r *ClusterRule) GetRuleStatus() *RuleStatus { return &r.Status }
+func (r *ClusterRule) AsRule() *Rule {
+	return &Rule{ObjectMeta: r.ObjectMeta, Spec: r.Spec, Status: r.Status}
+}
@@ -0,0 +1,270 @@
+package v1alpha1
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RuleOverlapIndex is the field index of rules by the overlap buckets, see RuleOverlapIndexFunc.
+	RuleOverlapIndex = "spec.match.overlap"
+	// RuleMacDirectIndex is the field index of rules by the workload macs and
+	// directs, see RuleMacDirectIndexFunc.
+	RuleMacDirectIndex = "spec.match.macDirect"
+)
+
+// Precedes reports whether the rule takes precedence over the other one when
+// their matches overlap. The rule with higher priority wins, ties are broken by
+// the older creation timestamp and then namespace/name. Data-plane agents must
+// use it to order rules instead of their own tie-breaking.
+func (r *Rule) Precedes(o *Rule) bool {
+	if r.Spec.Priority != o.Spec.Priority {
+		return r.Spec.Priority > o.Spec.Priority
+	}
+	if !r.CreationTimestamp.Equal(&o.CreationTimestamp) {
+		return r.CreationTimestamp.Before(&o.CreationTimestamp)
+	}
+	if r.Namespace != o.Namespace {
+		return r.Namespace < o.Namespace
+	}
+	return r.Name < o.Name
+}
+
+// SortByPrecedence sorts the rules into the effective order, the first rule
+// wins when matches overlap.
+func SortByPrecedence(rules []Rule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Precedes(&rules[j])
+	})
+}
+
+// Overlaps reports whether some traffic is matched by both rules.
+func (r *Rule) Overlaps(o *Rule) bool {
+	for _, a := range r.Expand() {
+		for _, b := range o.Expand() {
+			if a.Spec.Direct == b.Spec.Direct && a.Spec.Match.Overlaps(&b.Spec.Match) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Overlaps reports whether some packet is matched by both matches, an unset
+// field matches any value.
+func (m *RuleMatch) Overlaps(o *RuleMatch) bool {
+	if !macOverlaps(m.SrcMac, o.SrcMac) || !macOverlaps(m.DstMac, o.DstMac) {
+		return false
+	}
+	if !rangeOverlaps(m.vlanRange(), o.vlanRange()) {
+		return false
+	}
+	if !m.l3Overlaps(o) {
+		return false
+	}
+	if !cidrOverlaps(m.SrcCIDR, o.SrcCIDR) || !cidrOverlaps(m.DstCIDR, o.DstCIDR) {
+		return false
+	}
+	if m.Protocol != "" && o.Protocol != "" && m.Protocol != o.Protocol {
+		return false
+	}
+	return rangeOverlaps(m.SrcPort.portRange(), o.SrcPort.portRange()) &&
+		rangeOverlaps(m.DstPort.portRange(), o.DstPort.portRange())
+}
+
+// RuleOverlapIndexFunc indexes a Rule or ClusterRule by its mac buckets, rules
+// overlapping a rule are all found by RuleOverlapCandidateKeys of it.
+func RuleOverlapIndexFunc(obj client.Object) []string {
+	r, ok := obj.(RuleObject)
+	if !ok {
+		return nil
+	}
+	keys := sets.New[string]()
+	for _, d := range r.AsRule().Expand() {
+		m := &d.Spec.Match
+		keys.Insert(
+			string(d.Spec.Direct),
+			overlapKey(d.Spec.Direct, "src", literalMac(m.SrcMac)),
+			overlapKey(d.Spec.Direct, "dst", literalMac(m.DstMac)),
+		)
+	}
+	return sets.List(keys)
+}
+
+// RuleOverlapCandidateKeys returns the RuleOverlapIndex values to look up the
+// rules which may overlap the rule.
+func RuleOverlapCandidateKeys(r *Rule) []string {
+	keys := sets.New[string]()
+	for _, d := range r.Expand() {
+		src, dst := literalMac(d.Spec.Match.SrcMac), literalMac(d.Spec.Match.DstMac)
+		switch {
+		case src != "":
+			keys.Insert(overlapKey(d.Spec.Direct, "src", src), overlapKey(d.Spec.Direct, "src", ""))
+		case dst != "":
+			keys.Insert(overlapKey(d.Spec.Direct, "dst", dst), overlapKey(d.Spec.Direct, "dst", ""))
+		default:
+			keys.Insert(string(d.Spec.Direct))
+		}
+	}
+	return sets.List(keys)
+}
+
+// RuleMacDirectIndexFunc indexes a Rule or ClusterRule by direct/mac of the
+// workload macs, the srcMac of egress and the dstMac of ingress. A mac/mask
+// is not a workload mac and not indexed.
+func RuleMacDirectIndexFunc(obj client.Object) []string {
+	r, ok := obj.(RuleObject)
+	if !ok {
+		return nil
+	}
+	keys := sets.New[string]()
+	for _, d := range r.AsRule().Expand() {
+		if mac := workloadMac(d); mac != "" {
+			keys.Insert(string(d.Spec.Direct) + "/" + mac)
+		}
+	}
+	return sets.List(keys)
+}
+
+// workloadMac returns the lower case mac of the workload NIC of an expanded
+// rule, the srcMac of egress and the dstMac of ingress. It's empty for a
+// mac/mask.
+func workloadMac(r *Rule) string {
+	mac := r.Spec.Match.SrcMac
+	if r.Spec.Direct == Ingress {
+		mac = r.Spec.Match.DstMac
+	}
+	return strings.ToLower(literalMac(mac))
+}
+
+func overlapKey(d RuleDirect, field, mac string) string {
+	if mac == "" {
+		mac = "*"
+	}
+	return string(d) + "/" + field + "/" + strings.ToLower(mac)
+}
+
+// macOverlaps reports whether some mac is matched by both mac matches, the
+// bits in both masks must be the same.
+func macOverlaps(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	macA, maskA, errA := ParseMacMask(a)
+	macB, maskB, errB := ParseMacMask(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	for i := range macA {
+		if macA[i]&maskA[i]&maskB[i] != macB[i]&maskA[i]&maskB[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// literalMac returns the mac to index the rule by, a mac/mask is indexed as
+// any mac because it may overlap macs of other buckets.
+func literalMac(m string) string {
+	if IsMacMask(m) {
+		return ""
+	}
+	return m
+}
+
+func cidrOverlaps(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	_, na, errA := net.ParseCIDR(normalizeCIDR(a))
+	_, nb, errB := net.ParseCIDR(normalizeCIDR(b))
+	if errA != nil || errB != nil {
+		// invalid rules are not programmed, treat as overlapping to be safe
+		return true
+	}
+	return na.Contains(nb.IP) || nb.Contains(na.IP)
+}
+
+// rangeOverlaps compares inclusive ranges, nil means any value.
+func rangeOverlaps(a, b *[2]int32) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	return a[0] <= b[1] && b[0] <= a[1]
+}
+
+func (p *PortRange) portRange() *[2]int32 {
+	if p == nil {
+		return nil
+	}
+	end := p.End
+	if end == 0 {
+		end = p.Begin
+	}
+	return &[2]int32{p.Begin, end}
+}
+
+func (m *RuleMatch) vlanRange() *[2]int32 {
+	if m.VlanID == nil {
+		return nil
+	}
+	end := *m.VlanID
+	if m.VlanIDEnd != nil {
+		end = *m.VlanIDEnd
+	}
+	return &[2]int32{*m.VlanID, end}
+}
+
+func (m *RuleMatch) l3Overlaps(o *RuleMatch) bool {
+	typeA, ipA := m.l3Type()
+	typeB, ipB := o.l3Type()
+	if typeA != 0 && typeB != 0 {
+		return typeA == typeB
+	}
+	if ipA && typeB != 0 && !isIPEtherType(typeB) {
+		return false
+	}
+	if ipB && typeA != 0 && !isIPEtherType(typeA) {
+		return false
+	}
+	return true
+}
+
+// l3Type returns the ethertype implied by the match, zero for any, and
+// whether the match only matches ip packets.
+func (m *RuleMatch) l3Type() (uint16, bool) {
+	var t uint16
+	if m.EtherType != "" {
+		t, _ = m.EtherType.Value()
+	}
+	for _, c := range []string{m.SrcCIDR, m.DstCIDR} {
+		if c == "" {
+			continue
+		}
+		if ip, _, err := net.ParseCIDR(normalizeCIDR(c)); err == nil {
+			t = ipEtherType(ipFamily(ip))
+		}
+	}
+	switch m.Protocol {
+	case ProtocolICMP:
+		t = ipEtherType(4)
+	case ProtocolICMPv6:
+		t = ipEtherType(6)
+	}
+	return t, m.Protocol != "" || isIPEtherType(t)
+}
+
+func ipEtherType(family int) uint16 {
+	if family == 4 {
+		return etherTypeValues[EtherTypeIPv4]
+	}
+	return etherTypeValues[EtherTypeIPv6]
+}
+
+func isIPEtherType(t uint16) bool {
+	return t == etherTypeValues[EtherTypeIPv4] || t == etherTypeValues[EtherTypeIPv6]
+}
@@ -0,0 +1,261 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestRuleMatch_Overlaps(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b RuleMatch
+		want bool
+	}{
+		{
+			name: "same mac",
+			a:    RuleMatch{SrcMac: "00:11:22:33:44:55"},
+			b:    RuleMatch{SrcMac: "00:11:22:33:44:55"},
+			want: true,
+		},
+		{
+			name: "different mac",
+			a:    RuleMatch{SrcMac: "00:11:22:33:44:55"},
+			b:    RuleMatch{SrcMac: "00:11:22:33:44:66"},
+			want: false,
+		},
+		{
+			name: "mac case insensitive",
+			a:    RuleMatch{SrcMac: "00:11:22:33:44:AA"},
+			b:    RuleMatch{SrcMac: "00:11:22:33:44:aa"},
+			want: true,
+		},
+		{
+			name: "wildcard mac",
+			a:    RuleMatch{SrcMac: "00:11:22:33:44:55"},
+			b:    RuleMatch{DstMac: "00:11:22:33:44:66"},
+			want: true,
+		},
+		{
+			name: "mac in oui",
+			a:    RuleMatch{SrcMac: "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+			b:    RuleMatch{SrcMac: "00:50:56:12:34:56"},
+			want: true,
+		},
+		{
+			name: "mac out of oui",
+			a:    RuleMatch{SrcMac: "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+			b:    RuleMatch{SrcMac: "00:50:57:12:34:56"},
+			want: false,
+		},
+		{
+			name: "nested mac masks",
+			a:    RuleMatch{DstMac: "00:50:00:00:00:00/ff:ff:00:00:00:00"},
+			b:    RuleMatch{DstMac: "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+			want: true,
+		},
+		{
+			name: "disjoint mac masks",
+			a:    RuleMatch{DstMac: "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+			b:    RuleMatch{DstMac: "00:0c:29:00:00:00/ff:ff:ff:00:00:00"},
+			want: false,
+		},
+		{
+			name: "nested cidr",
+			a:    RuleMatch{SrcMac: "00:11:22:33:44:55", DstCIDR: "10.0.0.0/8"},
+			b:    RuleMatch{SrcMac: "00:11:22:33:44:55", DstCIDR: "10.1.1.1"},
+			want: true,
+		},
+		{
+			name: "disjoint cidr",
+			a:    RuleMatch{SrcCIDR: "10.0.0.0/24"},
+			b:    RuleMatch{SrcCIDR: "10.0.1.0/24"},
+			want: false,
+		},
+		{
+			name: "different ip family",
+			a:    RuleMatch{SrcCIDR: "10.0.0.0/24"},
+			b:    RuleMatch{DstCIDR: "fd00::/64"},
+			want: false,
+		},
+		{
+			name: "different protocol",
+			a:    RuleMatch{Protocol: ProtocolTCP},
+			b:    RuleMatch{Protocol: ProtocolUDP},
+			want: false,
+		},
+		{
+			name: "overlapping port range",
+			a:    RuleMatch{Protocol: ProtocolTCP, DstPort: &PortRange{Begin: 80, End: 90}},
+			b:    RuleMatch{Protocol: ProtocolTCP, DstPort: &PortRange{Begin: 90}},
+			want: true,
+		},
+		{
+			name: "disjoint port range",
+			a:    RuleMatch{Protocol: ProtocolTCP, DstPort: &PortRange{Begin: 80, End: 89}},
+			b:    RuleMatch{Protocol: ProtocolTCP, DstPort: &PortRange{Begin: 90}},
+			want: false,
+		},
+		{
+			name: "disjoint vlan",
+			a:    RuleMatch{VlanID: pointer.Int32(10), VlanIDEnd: pointer.Int32(20)},
+			b:    RuleMatch{VlanID: pointer.Int32(21)},
+			want: false,
+		},
+		{
+			name: "vlan in range",
+			a:    RuleMatch{VlanID: pointer.Int32(10), VlanIDEnd: pointer.Int32(20)},
+			b:    RuleMatch{VlanID: pointer.Int32(15)},
+			want: true,
+		},
+		{
+			name: "different ethertype",
+			a:    RuleMatch{EtherType: EtherTypeARP},
+			b:    RuleMatch{EtherType: EtherTypeLLDP},
+			want: false,
+		},
+		{
+			name: "ethertype implied by cidr",
+			a:    RuleMatch{EtherType: EtherTypeIPv6},
+			b:    RuleMatch{SrcCIDR: "10.0.0.0/8"},
+			want: false,
+		},
+		{
+			name: "protocol never matches arp",
+			a:    RuleMatch{EtherType: EtherTypeARP},
+			b:    RuleMatch{Protocol: ProtocolTCP},
+			want: false,
+		},
+		{
+			name: "protocol matches ipv6",
+			a:    RuleMatch{EtherType: EtherTypeIPv6},
+			b:    RuleMatch{Protocol: ProtocolTCP},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.a.Overlaps(&tt.b))
+			assert.Equal(t, tt.want, tt.b.Overlaps(&tt.a))
+		})
+	}
+}
+
+func TestRule_Overlaps(t *testing.T) {
+	a := &Rule{Spec: RuleSpec{Direct: Egress, Match: RuleMatch{SrcMac: "00:11:22:33:44:55"}}}
+	b := a.DeepCopy()
+	assert.True(t, a.Overlaps(b))
+	b.Spec.Direct = Ingress
+	assert.False(t, a.Overlaps(b))
+
+	both := &Rule{Spec: RuleSpec{Direct: Both, Match: RuleMatch{Mac: "00:11:22:33:44:55"}}}
+	assert.True(t, both.Overlaps(a))
+	b.Spec.Match = RuleMatch{DstMac: "00:11:22:33:44:66"}
+	assert.False(t, both.Overlaps(b))
+	b.Spec.Match = RuleMatch{DstMac: "00:11:22:33:44:55"}
+	assert.True(t, b.Overlaps(both))
+}
+
+func TestRule_Expand(t *testing.T) {
+	r := &Rule{Spec: RuleSpec{Direct: Both, Match: RuleMatch{Selector: &VMSelector{TowerVMs: []string{"vm1"}}}}}
+	assert.Empty(t, r.Expand())
+
+	r.Status.SelectedMacs = []SelectedMac{{Mac: "00:11:22:33:44:55"}, {Mac: "00:11:22:33:44:66"}}
+	expanded := r.Expand()
+	assert.Len(t, expanded, 4)
+	for _, e := range expanded {
+		assert.Nil(t, e.Spec.Match.Selector)
+		assert.Empty(t, e.Spec.Match.Mac)
+	}
+	assert.Equal(t, Egress, expanded[0].Spec.Direct)
+	assert.Equal(t, "00:11:22:33:44:55", expanded[0].Spec.Match.SrcMac)
+	assert.Equal(t, Ingress, expanded[3].Spec.Direct)
+	assert.Equal(t, "00:11:22:33:44:66", expanded[3].Spec.Match.DstMac)
+
+	literal := &Rule{Spec: RuleSpec{Direct: Egress, Match: RuleMatch{SrcMac: "00:11:22:33:44:55"}}}
+	assert.True(t, r.Overlaps(literal))
+	assert.Equal(t, []*Rule{literal}, literal.Expand())
+}
+
+func TestRule_ExpandMacs(t *testing.T) {
+	r := &Rule{Spec: RuleSpec{Direct: Egress, Match: RuleMatch{
+		SrcMacs: []string{"00:11:22:33:44:55", "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+		DstMacs: []string{"00:11:22:33:44:66", "00:11:22:33:44:77"},
+	}}}
+	expanded := r.Expand()
+	assert.Len(t, expanded, 4)
+	for _, e := range expanded {
+		assert.Nil(t, e.Spec.Match.SrcMacs)
+		assert.Nil(t, e.Spec.Match.DstMacs)
+	}
+	assert.Equal(t, "00:50:56:00:00:00/ff:ff:ff:00:00:00", expanded[3].Spec.Match.SrcMac)
+	assert.Equal(t, "00:11:22:33:44:77", expanded[3].Spec.Match.DstMac)
+
+	literal := &Rule{Spec: RuleSpec{Direct: Egress, Match: RuleMatch{SrcMac: "00:50:56:12:34:56"}}}
+	assert.True(t, r.Overlaps(literal))
+	literal.Spec.Match.SrcMac = "00:50:57:12:34:56"
+	assert.False(t, r.Overlaps(literal))
+}
+
+func TestSortByPrecedence(t *testing.T) {
+	now := time.Now()
+	newRule := func(name string, priority int32, created time.Time) Rule {
+		return Rule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       RuleSpec{Priority: priority},
+		}
+	}
+	rules := []Rule{
+		newRule("low", -1, now.Add(-time.Hour)),
+		newRule("b", 0, now),
+		newRule("newer", 0, now.Add(time.Minute)),
+		newRule("a", 0, now),
+		newRule("high", 10, now.Add(time.Hour)),
+	}
+	SortByPrecedence(rules)
+
+	var names []string
+	for _, r := range rules {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{"high", "a", "b", "newer", "low"}, names)
+}
+
+func TestRuleOverlapIndex(t *testing.T) {
+	index := map[string][]string{}
+	rules := []*Rule{
+		{ObjectMeta: metav1.ObjectMeta{Name: "src"}, Spec: RuleSpec{Direct: Egress, Match: RuleMatch{SrcMac: "00:11:22:33:44:55"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other-src"}, Spec: RuleSpec{Direct: Egress, Match: RuleMatch{SrcMac: "00:11:22:33:44:66"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "dst"}, Spec: RuleSpec{Direct: Egress, Match: RuleMatch{DstMac: "00:11:22:33:44:77"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cidr"}, Spec: RuleSpec{Direct: Egress, Match: RuleMatch{SrcCIDR: "10.0.0.0/8"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ingress"}, Spec: RuleSpec{Direct: Ingress, Match: RuleMatch{SrcMac: "00:11:22:33:44:55"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "both"}, Spec: RuleSpec{Direct: Both, Match: RuleMatch{Mac: "00:11:22:33:44:55"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "oui"}, Spec: RuleSpec{Direct: Egress, Match: RuleMatch{SrcMac: "00:11:22:00:00:00/ff:ff:ff:00:00:00"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "list"}, Spec: RuleSpec{Direct: Egress, Match: RuleMatch{DstMacs: []string{"00:11:22:33:44:77", "00:11:22:33:44:88"}}}},
+	}
+	for _, r := range rules {
+		for _, key := range RuleOverlapIndexFunc(r) {
+			index[key] = append(index[key], r.Name)
+		}
+	}
+	lookup := func(r *Rule) []string {
+		var names []string
+		for _, key := range RuleOverlapCandidateKeys(r) {
+			names = append(names, index[key]...)
+		}
+		return names
+	}
+
+	// every overlapping rule must be found by the candidate keys
+	for _, r := range rules {
+		for _, o := range rules {
+			if r.Overlaps(o) {
+				assert.Contains(t, lookup(r), o.Name, "%s overlaps %s", r.Name, o.Name)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"src", "dst", "cidr", "both", "oui", "list"}, lookup(rules[0]))
+}
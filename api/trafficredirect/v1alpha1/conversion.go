@@ -0,0 +1,285 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1beta1"
+)
+
+// v1alpha1 is a spoke of the conversion, the hub is the storage version v1beta1.
+// Structs with the same layout in both versions are converted by go type
+// conversion, so a field added to only one version fails the build here.
+
+var _ conversion.Convertible = &Rule{}
+var _ conversion.Convertible = &ClusterRule{}
+var _ conversion.Convertible = &RedirectTarget{}
+var _ conversion.Convertible = &RedirectExemption{}
+var _ conversion.Convertible = &RuleQuota{}
+
+// ConvertTo converts the rule to the hub version v1beta1.
+func (r *Rule) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.Rule)
+	dst.ObjectMeta = r.ObjectMeta
+	dst.Spec = ruleSpecToHub(r.Spec)
+	dst.Status = ruleStatusToHub(r.Status)
+	return nil
+}
+
+// ConvertFrom converts the rule from the hub version v1beta1.
+func (r *Rule) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.Rule)
+	r.ObjectMeta = src.ObjectMeta
+	r.Spec = ruleSpecFromHub(src.Spec)
+	r.Status = ruleStatusFromHub(src.Status)
+	return nil
+}
+
+// ConvertTo converts the cluster rule to the hub version v1beta1.
+func (r *ClusterRule) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.ClusterRule)
+	dst.ObjectMeta = r.ObjectMeta
+	dst.Spec = ruleSpecToHub(r.Spec)
+	dst.Status = ruleStatusToHub(r.Status)
+	return nil
+}
+
+// ConvertFrom converts the cluster rule from the hub version v1beta1.
+func (r *ClusterRule) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.ClusterRule)
+	r.ObjectMeta = src.ObjectMeta
+	r.Spec = ruleSpecFromHub(src.Spec)
+	r.Status = ruleStatusFromHub(src.Status)
+	return nil
+}
+
+// ConvertTo converts the redirect target to the hub version v1beta1.
+func (t *RedirectTarget) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.RedirectTarget)
+	dst.ObjectMeta = t.ObjectMeta
+	dst.Spec = v1beta1.RedirectTargetSpec{
+		Endpoints: convertSlice(t.Spec.Endpoints, func(e TargetEndpoint) v1beta1.TargetEndpoint { return v1beta1.TargetEndpoint(e) }),
+		Probe:     convertPtr(t.Spec.Probe, func(p TargetProbe) v1beta1.TargetProbe { return v1beta1.TargetProbe(p) }),
+	}
+	dst.Status = v1beta1.RedirectTargetStatus{
+		ObservedGeneration: t.Status.ObservedGeneration,
+		AvailableEndpoints: convertSlice(t.Status.AvailableEndpoints, func(e TargetEndpoint) v1beta1.TargetEndpoint { return v1beta1.TargetEndpoint(e) }),
+		AvailableCount:     t.Status.AvailableCount,
+		Endpoints:          convertSlice(t.Status.Endpoints, func(e TargetEndpointStatus) v1beta1.TargetEndpointStatus { return v1beta1.TargetEndpointStatus(e) }),
+	}
+	return nil
+}
+
+// ConvertFrom converts the redirect target from the hub version v1beta1.
+func (t *RedirectTarget) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.RedirectTarget)
+	t.ObjectMeta = src.ObjectMeta
+	t.Spec = RedirectTargetSpec{
+		Endpoints: convertSlice(src.Spec.Endpoints, func(e v1beta1.TargetEndpoint) TargetEndpoint { return TargetEndpoint(e) }),
+		Probe:     convertPtr(src.Spec.Probe, func(p v1beta1.TargetProbe) TargetProbe { return TargetProbe(p) }),
+	}
+	t.Status = RedirectTargetStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		AvailableEndpoints: convertSlice(src.Status.AvailableEndpoints, func(e v1beta1.TargetEndpoint) TargetEndpoint { return TargetEndpoint(e) }),
+		AvailableCount:     src.Status.AvailableCount,
+		Endpoints:          convertSlice(src.Status.Endpoints, func(e v1beta1.TargetEndpointStatus) TargetEndpointStatus { return TargetEndpointStatus(e) }),
+	}
+	return nil
+}
+
+// ConvertTo converts the redirect exemption to the hub version v1beta1.
+func (e *RedirectExemption) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.RedirectExemption)
+	dst.ObjectMeta = e.ObjectMeta
+	dst.Spec = v1beta1.RedirectExemptionSpec{
+		Macs: convertSlice(e.Spec.Macs, func(m string) string { return m }),
+		Traffic: convertSlice(e.Spec.Traffic, func(t ExemptTraffic) v1beta1.ExemptTraffic {
+			return v1beta1.ExemptTraffic{
+				EtherType: v1beta1.EtherType(t.EtherType),
+				Protocol:  v1beta1.Protocol(t.Protocol),
+				DstPort:   convertPtr(t.DstPort, func(p PortRange) v1beta1.PortRange { return v1beta1.PortRange(p) }),
+			}
+		}),
+		Description: e.Spec.Description,
+	}
+	return nil
+}
+
+// ConvertFrom converts the redirect exemption from the hub version v1beta1.
+func (e *RedirectExemption) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.RedirectExemption)
+	e.ObjectMeta = src.ObjectMeta
+	e.Spec = RedirectExemptionSpec{
+		Macs: convertSlice(src.Spec.Macs, func(m string) string { return m }),
+		Traffic: convertSlice(src.Spec.Traffic, func(t v1beta1.ExemptTraffic) ExemptTraffic {
+			return ExemptTraffic{
+				EtherType: EtherType(t.EtherType),
+				Protocol:  Protocol(t.Protocol),
+				DstPort:   convertPtr(t.DstPort, func(p v1beta1.PortRange) PortRange { return PortRange(p) }),
+			}
+		}),
+		Description: src.Spec.Description,
+	}
+	return nil
+}
+
+// ConvertTo converts the rule quota to the hub version v1beta1.
+func (q *RuleQuota) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.RuleQuota)
+	dst.ObjectMeta = q.ObjectMeta
+	dst.Spec = v1beta1.RuleQuotaSpec(q.Spec)
+	return nil
+}
+
+// ConvertFrom converts the rule quota from the hub version v1beta1.
+func (q *RuleQuota) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.RuleQuota)
+	q.ObjectMeta = src.ObjectMeta
+	q.Spec = RuleQuotaSpec(src.Spec)
+	return nil
+}
+
+func ruleSpecToHub(in RuleSpec) v1beta1.RuleSpec {
+	return v1beta1.RuleSpec{
+		Match: v1beta1.RuleMatch{
+			SrcMac:    in.Match.SrcMac,
+			DstMac:    in.Match.DstMac,
+			SrcMacs:   convertSlice(in.Match.SrcMacs, func(m string) string { return m }),
+			DstMacs:   convertSlice(in.Match.DstMacs, func(m string) string { return m }),
+			Mac:       in.Match.Mac,
+			SrcCIDR:   in.Match.SrcCIDR,
+			DstCIDR:   in.Match.DstCIDR,
+			Protocol:  v1beta1.Protocol(in.Match.Protocol),
+			SrcPort:   convertPtr(in.Match.SrcPort, func(p PortRange) v1beta1.PortRange { return v1beta1.PortRange(p) }),
+			DstPort:   convertPtr(in.Match.DstPort, func(p PortRange) v1beta1.PortRange { return v1beta1.PortRange(p) }),
+			VlanID:    convertPtr(in.Match.VlanID, func(v int32) int32 { return v }),
+			VlanIDEnd: convertPtr(in.Match.VlanIDEnd, func(v int32) int32 { return v }),
+			EtherType: v1beta1.EtherType(in.Match.EtherType),
+			Selector: convertPtr(in.Match.Selector, func(s VMSelector) v1beta1.VMSelector {
+				return v1beta1.VMSelector{
+					TowerVMs:      convertSlice(s.TowerVMs, func(id string) string { return id }),
+					TowerLabels:   convertSlice(s.TowerLabels, func(l TowerLabel) v1beta1.TowerLabel { return v1beta1.TowerLabel(l) }),
+					LabelSelector: s.LabelSelector.DeepCopy(),
+				}
+			}),
+		},
+		Direct:   v1beta1.RuleDirect(in.Direct),
+		Priority: in.Priority,
+		Suspend:  in.Suspend,
+		Schedule: convertPtr(in.Schedule, func(s RuleSchedule) v1beta1.RuleSchedule {
+			return v1beta1.RuleSchedule{
+				TimeZone: s.TimeZone,
+				Windows: convertSlice(s.Windows, func(w ScheduleWindow) v1beta1.ScheduleWindow {
+					return v1beta1.ScheduleWindow{Days: convertSlice(w.Days, func(d string) string { return d }), Start: w.Start, End: w.End}
+				}),
+			}
+		}),
+		ExpiresAt: convertPtr(in.ExpiresAt, func(t metav1.Time) metav1.Time { return t }),
+		TTL:       convertPtr(in.TTL, func(d metav1.Duration) metav1.Duration { return d }),
+		Action: convertPtr(in.Action, func(a RuleAction) v1beta1.RuleAction {
+			return v1beta1.RuleAction{
+				Mode:   v1beta1.ActionMode(a.Mode),
+				Target: v1beta1.RedirectTargetRef(a.Target),
+			}
+		}),
+		FailurePolicy: v1beta1.FailurePolicy(in.FailurePolicy),
+		SampleRate:    convertPtr(in.SampleRate, func(v int32) int32 { return v }),
+		TruncateBytes: convertPtr(in.TruncateBytes, func(v int32) int32 { return v }),
+		Option:        convertPtr(in.Option, func(o Option) v1beta1.Option { return v1beta1.Option(o) }),
+	}
+}
+
+func ruleSpecFromHub(in v1beta1.RuleSpec) RuleSpec {
+	return RuleSpec{
+		Match: RuleMatch{
+			SrcMac:    in.Match.SrcMac,
+			DstMac:    in.Match.DstMac,
+			SrcMacs:   convertSlice(in.Match.SrcMacs, func(m string) string { return m }),
+			DstMacs:   convertSlice(in.Match.DstMacs, func(m string) string { return m }),
+			Mac:       in.Match.Mac,
+			SrcCIDR:   in.Match.SrcCIDR,
+			DstCIDR:   in.Match.DstCIDR,
+			Protocol:  Protocol(in.Match.Protocol),
+			SrcPort:   convertPtr(in.Match.SrcPort, func(p v1beta1.PortRange) PortRange { return PortRange(p) }),
+			DstPort:   convertPtr(in.Match.DstPort, func(p v1beta1.PortRange) PortRange { return PortRange(p) }),
+			VlanID:    convertPtr(in.Match.VlanID, func(v int32) int32 { return v }),
+			VlanIDEnd: convertPtr(in.Match.VlanIDEnd, func(v int32) int32 { return v }),
+			EtherType: EtherType(in.Match.EtherType),
+			Selector: convertPtr(in.Match.Selector, func(s v1beta1.VMSelector) VMSelector {
+				return VMSelector{
+					TowerVMs:      convertSlice(s.TowerVMs, func(id string) string { return id }),
+					TowerLabels:   convertSlice(s.TowerLabels, func(l v1beta1.TowerLabel) TowerLabel { return TowerLabel(l) }),
+					LabelSelector: s.LabelSelector.DeepCopy(),
+				}
+			}),
+		},
+		Direct:   RuleDirect(in.Direct),
+		Priority: in.Priority,
+		Suspend:  in.Suspend,
+		Schedule: convertPtr(in.Schedule, func(s v1beta1.RuleSchedule) RuleSchedule {
+			return RuleSchedule{
+				TimeZone: s.TimeZone,
+				Windows: convertSlice(s.Windows, func(w v1beta1.ScheduleWindow) ScheduleWindow {
+					return ScheduleWindow{Days: convertSlice(w.Days, func(d string) string { return d }), Start: w.Start, End: w.End}
+				}),
+			}
+		}),
+		ExpiresAt: convertPtr(in.ExpiresAt, func(t metav1.Time) metav1.Time { return t }),
+		TTL:       convertPtr(in.TTL, func(d metav1.Duration) metav1.Duration { return d }),
+		Action: convertPtr(in.Action, func(a v1beta1.RuleAction) RuleAction {
+			return RuleAction{
+				Mode:   ActionMode(a.Mode),
+				Target: RedirectTargetRef(a.Target),
+			}
+		}),
+		FailurePolicy: FailurePolicy(in.FailurePolicy),
+		SampleRate:    convertPtr(in.SampleRate, func(v int32) int32 { return v }),
+		TruncateBytes: convertPtr(in.TruncateBytes, func(v int32) int32 { return v }),
+		Option:        convertPtr(in.Option, func(o v1beta1.Option) Option { return Option(o) }),
+	}
+}
+
+func ruleStatusToHub(in RuleStatus) v1beta1.RuleStatus {
+	return v1beta1.RuleStatus{
+		ObservedGeneration: in.ObservedGeneration,
+		Conditions:         convertSlice(in.Conditions, func(c metav1.Condition) metav1.Condition { return c }),
+		Nodes:              convertSlice(in.Nodes, func(n RuleNodeStatus) v1beta1.RuleNodeStatus { return v1beta1.RuleNodeStatus(n) }),
+		ProgrammedNodes:    in.ProgrammedNodes,
+		FailedNodes:        in.FailedNodes,
+		Target:             convertPtr(in.Target, func(t ResolvedTarget) v1beta1.ResolvedTarget { return v1beta1.ResolvedTarget(t) }),
+		SelectedMacs:       convertSlice(in.SelectedMacs, func(m SelectedMac) v1beta1.SelectedMac { return v1beta1.SelectedMac(m) }),
+	}
+}
+
+func ruleStatusFromHub(in v1beta1.RuleStatus) RuleStatus {
+	return RuleStatus{
+		ObservedGeneration: in.ObservedGeneration,
+		Conditions:         convertSlice(in.Conditions, func(c metav1.Condition) metav1.Condition { return c }),
+		Nodes:              convertSlice(in.Nodes, func(n v1beta1.RuleNodeStatus) RuleNodeStatus { return RuleNodeStatus(n) }),
+		ProgrammedNodes:    in.ProgrammedNodes,
+		FailedNodes:        in.FailedNodes,
+		Target:             convertPtr(in.Target, func(t v1beta1.ResolvedTarget) ResolvedTarget { return ResolvedTarget(t) }),
+		SelectedMacs:       convertSlice(in.SelectedMacs, func(m v1beta1.SelectedMac) SelectedMac { return SelectedMac(m) }),
+	}
+}
+
+// convertSlice converts items of the slice with f, nil stays nil.
+func convertSlice[S, D any](in []S, f func(S) D) []D {
+	if in == nil {
+		return nil
+	}
+	out := make([]D, len(in))
+	for i := range in {
+		out[i] = f(in[i])
+	}
+	return out
+}
+
+// convertPtr converts the value the pointer points to with f, nil stays nil.
+func convertPtr[S, D any](in *S, f func(S) D) *D {
+	if in == nil {
+		return nil
+	}
+	out := f(*in)
+	return &out
+}
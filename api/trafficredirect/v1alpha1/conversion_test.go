@@ -0,0 +1,90 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1beta1"
+)
+
+const fuzzIters = 200
+
+// newFuzzer fills all fields except TypeMeta, which is set by the conversion webhook.
+func newFuzzer() *fuzz.Fuzzer {
+	return fuzz.New().NilChance(0.2).Funcs(
+		func(t *metav1.TypeMeta, _ fuzz.Continue) { *t = metav1.TypeMeta{} },
+	)
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		spoke func() conversion.Convertible
+		hub   func() conversion.Hub
+	}{
+		{
+			name:  "Rule",
+			spoke: func() conversion.Convertible { return &Rule{} },
+			hub:   func() conversion.Hub { return &v1beta1.Rule{} },
+		},
+		{
+			name:  "ClusterRule",
+			spoke: func() conversion.Convertible { return &ClusterRule{} },
+			hub:   func() conversion.Hub { return &v1beta1.ClusterRule{} },
+		},
+		{
+			name:  "RedirectTarget",
+			spoke: func() conversion.Convertible { return &RedirectTarget{} },
+			hub:   func() conversion.Hub { return &v1beta1.RedirectTarget{} },
+		},
+		{
+			name:  "RedirectExemption",
+			spoke: func() conversion.Convertible { return &RedirectExemption{} },
+			hub:   func() conversion.Hub { return &v1beta1.RedirectExemption{} },
+		},
+		{
+			name:  "RuleQuota",
+			spoke: func() conversion.Convertible { return &RuleQuota{} },
+			hub:   func() conversion.Hub { return &v1beta1.RuleQuota{} },
+		},
+	}
+	f := newFuzzer()
+	for _, tt := range tests {
+		t.Run(tt.name+" spoke-hub-spoke", func(t *testing.T) {
+			for i := 0; i < fuzzIters; i++ {
+				in, hub, out := tt.spoke(), tt.hub(), tt.spoke()
+				f.Fuzz(in)
+				assert.NoError(t, in.ConvertTo(hub))
+				assert.NoError(t, out.ConvertFrom(hub))
+				assert.Equal(t, in, out)
+			}
+		})
+		t.Run(tt.name+" hub-spoke-hub", func(t *testing.T) {
+			for i := 0; i < fuzzIters; i++ {
+				in, spoke, out := tt.hub(), tt.spoke(), tt.hub()
+				f.Fuzz(in)
+				assert.NoError(t, spoke.ConvertFrom(in))
+				assert.NoError(t, spoke.ConvertTo(out))
+				assert.Equal(t, in, out)
+			}
+		})
+	}
+}
+
+func TestConversionNotAliased(t *testing.T) {
+	vlan := int32(100)
+	r := &Rule{Spec: RuleSpec{
+		Direct: Egress,
+		Match:  RuleMatch{SrcMac: "00:11:22:33:44:55", VlanID: &vlan, SrcPort: &PortRange{Begin: 80}},
+	}}
+	hub := &v1beta1.Rule{}
+	assert.NoError(t, r.ConvertTo(hub))
+	*hub.Spec.Match.VlanID = 200
+	hub.Spec.Match.SrcPort.Begin = 443
+	assert.Equal(t, int32(100), *r.Spec.Match.VlanID)
+	assert.Equal(t, int32(80), r.Spec.Match.SrcPort.Begin)
+}
@@ -0,0 +1,198 @@
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var etherTypeValues = map[EtherType]uint16{
+	EtherTypeIPv4: 0x0800,
+	EtherTypeARP:  0x0806,
+	EtherTypeRARP: 0x8035,
+	EtherTypeIPv6: 0x86dd,
+	EtherTypeLLDP: 0x88cc,
+}
+
+// Value returns the numeric ethertype, it accepts the known names and hex values.
+func (e EtherType) Value() (uint16, error) {
+	if v, ok := etherTypeValues[e]; ok {
+		return v, nil
+	}
+	if !strings.HasPrefix(string(e), "0x") {
+		return 0, fmt.Errorf("ethertype %s is unknown", e)
+	}
+	v, err := strconv.ParseUint(string(e)[2:], 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("ethertype %s is invalid: %s", e, err)
+	}
+	// values below 0x0600 are 802.3 frame length
+	if v < 0x0600 {
+		return 0, fmt.Errorf("ethertype %s is less than 0x0600", e)
+	}
+	return uint16(v), nil
+}
+
+// Normalize returns the canonical form of the ethertype: the name for known
+// types and lowercase hex otherwise.
+func (e EtherType) Normalize() EtherType {
+	n := EtherType(strings.ToLower(string(e)))
+	v, err := n.Value()
+	if err != nil {
+		return n
+	}
+	for name, value := range etherTypeValues {
+		if value == v {
+			return name
+		}
+	}
+	return EtherType(fmt.Sprintf("0x%04x", v))
+}
+
+// ParseMacMask parses a mac or mac/mask, the mask of a single mac is all ones.
+// Agents program the masked matches with it.
+func ParseMacMask(s string) (net.HardwareAddr, net.HardwareAddr, error) {
+	mac, mask, masked := strings.Cut(s, "/")
+	addr, err := ParseMac(mac)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !masked {
+		return addr, net.HardwareAddr(bytes.Repeat([]byte{0xff}, 6)), nil
+	}
+	m, err := ParseMac(mask)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mac mask %s is invalid", mask)
+	}
+	return addr, m, nil
+}
+
+// ParseMac parses a 48 bits mac in any common notation: aa:bb:cc:dd:ee:ff,
+// AA-BB-CC-DD-EE-FF, aabb.ccdd.eeff and the bare aabbccddeeff.
+func ParseMac(s string) (net.HardwareAddr, error) {
+	if len(s) == 12 && !strings.ContainsAny(s, ":-.") {
+		addr, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("mac %s is invalid", s)
+		}
+		return addr, nil
+	}
+	addr, err := net.ParseMAC(s)
+	if err != nil || len(addr) != 6 {
+		return nil, fmt.Errorf("mac %s is invalid", s)
+	}
+	return addr, nil
+}
+
+// IsMacMask reports whether the mac match is a mac/mask matching a range of macs.
+func IsMacMask(s string) bool {
+	_, mask, err := ParseMacMask(s)
+	return err == nil && !bytes.Equal(mask, bytes.Repeat([]byte{0xff}, 6))
+}
+
+// normalizeMac returns the canonical form of a mac match: lowercase colon
+// form, the bits out of the mask cleared and the mask omitted when it's all
+// ones. Invalid value is kept as it is and rejected by validation.
+func normalizeMac(s string) string {
+	if s == "" {
+		return s
+	}
+	addr, mask, err := ParseMacMask(s)
+	if err != nil {
+		return s
+	}
+	if !IsMacMask(s) {
+		return addr.String()
+	}
+	for i := range addr {
+		addr[i] &= mask[i]
+	}
+	return addr.String() + "/" + mask.String()
+}
+
+// HashKey returns the key to select an endpoint of RedirectTarget for the rule.
+// It's the mac of the workload NIC, so ingress and egress traffic of the NIC
+// are sent to the same endpoint.
+func (r *Rule) HashKey() string {
+	m := &r.Spec.Match
+	switch {
+	case r.Spec.Direct == Both && m.Mac != "":
+		return m.Mac
+	case r.Spec.Direct == Ingress && m.DstMac != "":
+		return m.DstMac
+	case m.SrcMac != "":
+		return m.SrcMac
+	case m.DstMac != "":
+		return m.DstMac
+	}
+	return r.Namespace + "/" + r.Name
+}
+
+// Expand returns the rule as concrete rules of a single direct and literal
+// macs. A rule of match.selector is expanded into one rule per selected mac in
+// status, a rule of srcMacs or dstMacs is expanded into one rule per pair of
+// the macs, and a rule of direct both is split into an egress rule matching
+// srcMac and an ingress rule matching dstMac. Other rules are returned as
+// they are.
+func (r *Rule) Expand() []*Rule {
+	rules := []*Rule{r}
+	if r.Spec.Match.Selector != nil {
+		rules = make([]*Rule, 0, len(r.Status.SelectedMacs))
+		for _, selected := range r.Status.SelectedMacs {
+			c := r.DeepCopy()
+			c.Spec.Match.Selector = nil
+			switch r.Spec.Direct {
+			case Egress:
+				c.Spec.Match.SrcMac = selected.Mac
+			case Ingress:
+				c.Spec.Match.DstMac = selected.Mac
+			case Both:
+				c.Spec.Match.Mac = selected.Mac
+			}
+			rules = append(rules, c)
+		}
+	}
+
+	if len(r.Spec.Match.SrcMacs) != 0 || len(r.Spec.Match.DstMacs) != 0 {
+		rules = expandMacs(rules)
+	}
+
+	expanded := make([]*Rule, 0, 2*len(rules))
+	for _, c := range rules {
+		if c.Spec.Direct != Both {
+			expanded = append(expanded, c)
+			continue
+		}
+		egress, ingress := c.DeepCopy(), c.DeepCopy()
+		egress.Spec.Direct, ingress.Spec.Direct = Egress, Ingress
+		egress.Spec.Match.SrcMac, ingress.Spec.Match.DstMac = c.Spec.Match.Mac, c.Spec.Match.Mac
+		egress.Spec.Match.Mac, ingress.Spec.Match.Mac = "", ""
+		expanded = append(expanded, egress, ingress)
+	}
+	return expanded
+}
+
+func expandMacs(rules []*Rule) []*Rule {
+	expanded := make([]*Rule, 0, len(rules))
+	for _, c := range rules {
+		srcs, dsts := c.Spec.Match.SrcMacs, c.Spec.Match.DstMacs
+		if len(srcs) == 0 {
+			srcs = []string{c.Spec.Match.SrcMac}
+		}
+		if len(dsts) == 0 {
+			dsts = []string{c.Spec.Match.DstMac}
+		}
+		for _, src := range srcs {
+			for _, dst := range dsts {
+				e := c.DeepCopy()
+				e.Spec.Match.SrcMacs, e.Spec.Match.DstMacs = nil, nil
+				e.Spec.Match.SrcMac, e.Spec.Match.DstMac = src, dst
+				expanded = append(expanded, e)
+			}
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,30 @@
+package v1alpha1
+
+import "math"
+
+const (
+	// MaxSampleRate is the largest 1-in-N rate, agents sample with a uint16 probability.
+	MaxSampleRate = math.MaxUint16
+	// MinTruncateBytes keeps at least the ethernet, ip and transport headers.
+	MinTruncateBytes = 64
+	MaxTruncateBytes = math.MaxUint16
+)
+
+// SampleProbability returns the probability of the packets sent to the target
+// in units of 1/65535, it's the probability of the openflow sample action.
+// Every packet is sent when the rule isn't sampled.
+func (s *RuleSpec) SampleProbability() uint16 {
+	if s.SampleRate == nil || *s.SampleRate <= 1 {
+		return math.MaxUint16
+	}
+	return uint16(math.MaxUint16 / *s.SampleRate)
+}
+
+// SnapLength returns the length the packets sent to the target are truncated
+// to, 0 means the packets are sent entirely.
+func (s *RuleSpec) SnapLength() uint16 {
+	if s.TruncateBytes == nil {
+		return 0
+	}
+	return uint16(*s.TruncateBytes)
+}
@@ -0,0 +1,125 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ admission.Defaulter = &RedirectExemption{}
+var _ admission.Validator = &RedirectExemption{}
+
+func (r *RedirectExemption) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
+
+// Default rewrites the macs and traffic into the canonical form the same way
+// as the match of rules, exempt macs are compared with the macs of rules.
+func (r *RedirectExemption) Default() {
+	klog.Infof("Start to modify redirect exemption %v", r)
+	for i := range r.Spec.Macs {
+		r.Spec.Macs[i] = normalizeMac(r.Spec.Macs[i])
+	}
+	for i := range r.Spec.Traffic {
+		t := &r.Spec.Traffic[i]
+		t.EtherType = t.EtherType.Normalize()
+		t.Protocol = normalizeProtocol(t.Protocol)
+		if t.DstPort != nil && t.DstPort.End == 0 {
+			t.DstPort.End = t.DstPort.Begin
+		}
+	}
+}
+
+func (r *RedirectExemption) ValidateCreate() (admission.Warnings, error) {
+	klog.Infof("Start to validate create redirect exemption %v", r)
+	return nil, r.Validate()
+}
+
+func (r *RedirectExemption) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
+	klog.Infof("Start to validate update redirect exemption %v", r)
+	return nil, r.Validate()
+}
+
+func (r *RedirectExemption) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+// Validate checks the macs the same way as the mac lists of rules, a zero
+// mask exempts any mac and is rejected unless it's allowed by annotation.
+func (r *RedirectExemption) Validate() error {
+	if err := (&RuleSpec{}).validateMacList("macs", "", r.Spec.Macs, newValidateOptions(r)); err != nil {
+		return err
+	}
+	for i := range r.Spec.Traffic {
+		t := &r.Spec.Traffic[i]
+		if t.EtherType != "" {
+			if _, err := t.EtherType.Value(); err != nil {
+				return err
+			}
+		}
+		if t.DstPort == nil {
+			continue
+		}
+		if t.Protocol != ProtocolTCP && t.Protocol != ProtocolUDP && t.Protocol != ProtocolSCTP {
+			return fmt.Errorf("port match requires protocol TCP, UDP or SCTP")
+		}
+		if err := validatePortRange(t.DstPort); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExemptsMac reports whether the traffic of the mac is exempt.
+func (s *RedirectExemptionSpec) ExemptsMac(mac string) bool {
+	for _, m := range s.Macs {
+		if macOverlaps(m, mac) {
+			return true
+		}
+	}
+	return false
+}
+
+// Catches returns the exempt traffic matched by the concrete match, see
+// Rule.Expand. Exempt macs are caught by the macs of the match overlapping
+// them, exempt protocols are only caught by the match restricting ethertype or
+// protocol, other traffic of them is bypassed by agents before rules.
+func (s *RedirectExemptionSpec) Catches(m *RuleMatch) []string {
+	var caught []string
+	for _, mac := range s.Macs {
+		if (m.SrcMac != "" && macOverlaps(mac, m.SrcMac)) || (m.DstMac != "" && macOverlaps(mac, m.DstMac)) {
+			caught = append(caught, "mac "+mac)
+		}
+	}
+	if m.EtherType == "" && m.Protocol == "" {
+		return caught
+	}
+	for i := range s.Traffic {
+		t := &s.Traffic[i]
+		if m.Overlaps(&RuleMatch{EtherType: t.EtherType, Protocol: t.Protocol, DstPort: t.DstPort}) {
+			caught = append(caught, t.String())
+		}
+	}
+	return caught
+}
+
+func (t *ExemptTraffic) String() string {
+	var parts []string
+	if t.EtherType != "" {
+		parts = append(parts, "ethertype "+string(t.EtherType))
+	}
+	if t.Protocol != "" {
+		parts = append(parts, "protocol "+string(t.Protocol))
+	}
+	if p := t.DstPort.portRange(); p != nil {
+		parts = append(parts, fmt.Sprintf("dst port %d-%d", p[0], p[1]))
+	}
+	if len(parts) == 0 {
+		return "any traffic"
+	}
+	return strings.Join(parts, " ")
+}
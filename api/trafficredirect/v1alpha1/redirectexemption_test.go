@@ -0,0 +1,156 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRedirectExemption_Catches(t *testing.T) {
+	e := &RedirectExemptionSpec{
+		Macs: []string{"00:11:22:33:44:01", "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+		Traffic: []ExemptTraffic{
+			{EtherType: EtherTypeARP},
+			{Protocol: ProtocolUDP, DstPort: &PortRange{Begin: 67, End: 68}},
+		},
+	}
+	assert.True(t, e.ExemptsMac("00:50:56:aa:bb:cc"))
+	assert.False(t, e.ExemptsMac("00:11:22:33:44:02"))
+
+	tests := []struct {
+		name  string
+		match RuleMatch
+		want  []string
+	}{
+		{
+			name:  "not exempt",
+			match: RuleMatch{SrcMac: "00:11:22:33:44:02"},
+		},
+		{
+			name:  "exempt mac",
+			match: RuleMatch{SrcMac: "00:11:22:33:44:02", DstMac: "00:11:22:33:44:01"},
+			want:  []string{"mac 00:11:22:33:44:01"},
+		},
+		{
+			name:  "oui covers exempt macs",
+			match: RuleMatch{SrcMac: "00:50:00:00:00:00/ff:ff:00:00:00:00"},
+			want:  []string{"mac 00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+		},
+		{
+			name:  "exempt ethertype",
+			match: RuleMatch{SrcMac: "00:11:22:33:44:02", EtherType: EtherTypeARP},
+			want:  []string{"ethertype arp"},
+		},
+		{
+			name:  "exempt protocol",
+			match: RuleMatch{SrcMac: "00:11:22:33:44:02", Protocol: ProtocolUDP},
+			want:  []string{"protocol UDP dst port 67-68"},
+		},
+		{
+			name:  "disjoint port",
+			match: RuleMatch{SrcMac: "00:11:22:33:44:02", Protocol: ProtocolUDP, DstPort: &PortRange{Begin: 53, End: 53}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, e.Catches(&tt.match))
+		})
+	}
+}
+
+func TestRuleExemptionWarnings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	v := &RuleValidator{Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&RedirectExemption{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway"},
+		Spec:       RedirectExemptionSpec{Macs: []string{"00:11:22:33:44:01"}},
+	}).WithIndex(&Rule{}, RuleMacDirectIndex, RuleMacDirectIndexFunc).
+		WithIndex(&ClusterRule{}, RuleMacDirectIndex, RuleMacDirectIndexFunc).Build()}
+
+	r := &Rule{Spec: RuleSpec{Direct: Both, Match: RuleMatch{Mac: "00:11:22:33:44:01"}}}
+	warnings, err := v.ValidateCreate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rule matches mac 00:11:22:33:44:01 exempted by RedirectExemption gateway, the traffic is not redirected"}, []string(warnings))
+
+	r.Spec.Match.Mac = "00:11:22:33:44:02"
+	warnings, err = v.ValidateUpdate(context.Background(), nil, r)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestRedirectExemptionDefault(t *testing.T) {
+	e := &RedirectExemption{Spec: RedirectExemptionSpec{
+		Macs:    []string{"00-11-22-33-44-AA", "0050.56aa.bbcc/ffff.ff00.0000"},
+		Traffic: []ExemptTraffic{{EtherType: "LLDP"}, {Protocol: "udp", DstPort: &PortRange{Begin: 67}}},
+	}}
+	e.Default()
+	assert.Equal(t, []string{"00:11:22:33:44:aa", "00:50:56:00:00:00/ff:ff:ff:00:00:00"}, e.Spec.Macs)
+	assert.Equal(t, EtherTypeLLDP, e.Spec.Traffic[0].EtherType)
+	assert.Equal(t, ProtocolUDP, e.Spec.Traffic[1].Protocol)
+	assert.Equal(t, int32(67), e.Spec.Traffic[1].DstPort.End)
+	assert.NoError(t, e.Validate())
+}
+
+func TestRedirectExemptionValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		spec        RedirectExemptionSpec
+		errorText   string
+	}{
+		{
+			name: "valid",
+			spec: RedirectExemptionSpec{
+				Macs:    []string{"00:11:22:33:44:01", "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+				Traffic: []ExemptTraffic{{EtherType: EtherTypeARP}, {Protocol: ProtocolUDP, DstPort: &PortRange{Begin: 67, End: 68}}},
+			},
+		},
+		{
+			name:      "invalid mac",
+			spec:      RedirectExemptionSpec{Macs: []string{"00:11:22:33:44"}},
+			errorText: "mac 00:11:22:33:44 is invalid",
+		},
+		{
+			name:      "duplicated mac",
+			spec:      RedirectExemptionSpec{Macs: []string{"00:11:22:33:44:01", "00:11:22:33:44:01"}},
+			errorText: "mac 00:11:22:33:44:01 is duplicated in macs",
+		},
+		{
+			name:      "zero mask",
+			spec:      RedirectExemptionSpec{Macs: []string{"00:00:00:00:00:00/00:00:00:00:00:00"}},
+			errorText: "has zero mask which matches any mac",
+		},
+		{
+			name:        "zero mask allowed",
+			annotations: map[string]string{AnnotationAllowZeroMacMask: "true"},
+			spec:        RedirectExemptionSpec{Macs: []string{"00:00:00:00:00:00/00:00:00:00:00:00"}},
+		},
+		{
+			name:      "unknown ethertype",
+			spec:      RedirectExemptionSpec{Traffic: []ExemptTraffic{{EtherType: "ipx"}}},
+			errorText: "ethertype ipx is unknown",
+		},
+		{
+			name:      "port without protocol",
+			spec:      RedirectExemptionSpec{Traffic: []ExemptTraffic{{DstPort: &PortRange{Begin: 67}}}},
+			errorText: "port match requires protocol TCP, UDP or SCTP",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &RedirectExemption{ObjectMeta: metav1.ObjectMeta{Name: "e1", Annotations: tt.annotations}, Spec: tt.spec}
+			_, err := e.ValidateCreate()
+			if tt.errorText == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errorText)
+			_, err = e.ValidateUpdate(e.DeepCopy())
+			assert.ErrorContains(t, err, tt.errorText)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=redirectexemptions,shortName=trex,scope=Cluster
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RedirectExemption is the traffic never redirected, e.g. the traffic of
+// gateway macs, the NICs of the DPI appliance and control-plane protocols.
+// Agents bypass the exempt traffic before rules, the webhook warns rules
+// matching it and no rule is generated for exempt Tower NICs.
+type RedirectExemption struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RedirectExemptionSpec `json:"spec"`
+}
+
+type RedirectExemptionSpec struct {
+	// Macs or mac/masks whose traffic is never redirected.
+	// +kubebuilder:validation:items:Pattern=`^([0-9a-f]{2}:){5}[0-9a-f]{2}(/([0-9a-f]{2}:){5}[0-9a-f]{2})?$`
+	Macs []string `json:"macs,omitempty"`
+	// Traffic of the protocols never redirected, e.g. arp, ND, DHCP and LLDP.
+	Traffic []ExemptTraffic `json:"traffic,omitempty"`
+	// Why the traffic is exempt.
+	Description string `json:"description,omitempty"`
+}
+
+// ExemptTraffic matches packets by all the fields, an unset field matches any value.
+type ExemptTraffic struct {
+	// EtherType name: ipv4, ipv6, arp, rarp, lldp, or a hex value like 0x88cc.
+	EtherType EtherType `json:"etherType,omitempty"`
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP;ICMP;ICMPv6
+	Protocol Protocol `json:"protocol,omitempty"`
+	// Destination port range, only for protocol TCP, UDP and SCTP.
+	DstPort *PortRange `json:"dstPort,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type RedirectExemptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedirectExemption `json:"items"`
+}
@@ -0,0 +1,45 @@
+package v1alpha1
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Pick selects an available endpoint for the key with weighted rendezvous
+// hashing. A key only moves to another endpoint when its endpoint becomes
+// unavailable, or an endpoint with higher score for it becomes available.
+func (s *RedirectTargetStatus) Pick(key string) *TargetEndpoint {
+	var best *TargetEndpoint
+	var bestScore float64
+	for i := range s.AvailableEndpoints {
+		ep := &s.AvailableEndpoints[i]
+		score := rendezvousScore(ep.Name, key, ep.Weight)
+		if best == nil || score > bestScore {
+			best, bestScore = ep, score
+		}
+	}
+	return best
+}
+
+func rendezvousScore(endpoint, key string, weight int32) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(endpoint))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	// map the hash to (0, 1)
+	u := (float64(mix64(h.Sum64())>>11) + 0.5) / (1 << 53)
+	if weight < 1 {
+		weight = 1
+	}
+	return -float64(weight) / math.Log(u)
+}
+
+// mix64 is the splitmix64 finalizer, fnv has poor avalanche on short keys.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
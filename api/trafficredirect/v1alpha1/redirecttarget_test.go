@@ -0,0 +1,57 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirectTargetStatus_Pick(t *testing.T) {
+	s := &RedirectTargetStatus{}
+	assert.Nil(t, s.Pick("aa:bb:cc:dd:ee:ff"))
+
+	for i := 0; i < 4; i++ {
+		s.AvailableEndpoints = append(s.AvailableEndpoints, TargetEndpoint{Name: fmt.Sprintf("ep%d", i), Weight: 1})
+	}
+	keys := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprintf("00:00:00:00:%02x:%02x", i/256, i%256))
+	}
+
+	picked := map[string]string{}
+	count := map[string]int{}
+	for _, k := range keys {
+		ep := s.Pick(k)
+		assert.NotNil(t, ep)
+		assert.Equal(t, ep.Name, s.Pick(k).Name, "pick must be stable")
+		picked[k] = ep.Name
+		count[ep.Name]++
+	}
+	for name, c := range count {
+		assert.Greater(t, c, 150, "endpoint %s is picked too few", name)
+	}
+
+	// remove ep1, only keys on ep1 move
+	removed := &RedirectTargetStatus{AvailableEndpoints: []TargetEndpoint{
+		s.AvailableEndpoints[0], s.AvailableEndpoints[2], s.AvailableEndpoints[3],
+	}}
+	for _, k := range keys {
+		ep := removed.Pick(k)
+		if picked[k] != "ep1" {
+			assert.Equal(t, picked[k], ep.Name)
+		}
+	}
+}
+
+func TestRedirectTargetStatus_PickWeight(t *testing.T) {
+	s := &RedirectTargetStatus{AvailableEndpoints: []TargetEndpoint{
+		{Name: "small", Weight: 1},
+		{Name: "large", Weight: 3},
+	}}
+	count := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		count[s.Pick(fmt.Sprintf("key-%d", i)).Name]++
+	}
+	assert.Greater(t, count["large"], 2*count["small"])
+}
@@ -0,0 +1,86 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=redirecttargets,shortName=trt
+// +kubebuilder:printcolumn:name="available",type="integer",JSONPath=".status.availableCount"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RedirectTarget is a group of appliance endpoints, rules referring to it are
+// spread across the available endpoints.
+type RedirectTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedirectTargetSpec   `json:"spec"`
+	Status RedirectTargetStatus `json:"status,omitempty"`
+}
+
+type RedirectTargetSpec struct {
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	Endpoints []TargetEndpoint `json:"endpoints"`
+	// Health probe of the endpoints, all endpoints are available when unset.
+	Probe *TargetProbe `json:"probe,omitempty"`
+}
+
+type TargetEndpoint struct {
+	// Name of the endpoint, unique in the target.
+	Name string `json:"name"`
+	// MAC of the appliance NIC the traffic is sent to.
+	// +kubebuilder:validation:Pattern=`^([0-9a-f]{2}:){5}[0-9a-f]{2}$`
+	Mac string `json:"mac"`
+	// Address to probe the health of the endpoint, in form of host:port.
+	Address string `json:"address,omitempty"`
+	// Relative weight when spreading rules across endpoints.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	Weight int32 `json:"weight,omitempty"`
+}
+
+type TargetProbe struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// Consecutive failures for an available endpoint to be unavailable.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+type RedirectTargetStatus struct {
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Endpoints which pass the health probe, in the order of spec.
+	AvailableEndpoints []TargetEndpoint `json:"availableEndpoints,omitempty"`
+	AvailableCount     int32            `json:"availableCount,omitempty"`
+	// +listType=map
+	// +listMapKey=name
+	Endpoints []TargetEndpointStatus `json:"endpoints,omitempty"`
+}
+
+type TargetEndpointStatus struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	// Error of the last failed probe.
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type RedirectTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedirectTarget `json:"items"`
+}
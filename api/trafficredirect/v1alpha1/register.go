@@ -1,6 +1,6 @@
-// Package v1alpha1 contains API Schema definitions for the internal v1alpha1 API group
+// Package v1alpha1 contains API Schema definitions for the v1alpha1 API group
 // +kubebuilder:object:generate=true
-// +groupName=ipam.everoute.io
+// +groupName=tr.everoute.io
 package v1alpha1
 
 import (
@@ -12,6 +12,14 @@ func init() {
 	SchemeBuilder.Register(
 		&Rule{},
 		&RuleList{},
+		&ClusterRule{},
+		&ClusterRuleList{},
+		&RedirectTarget{},
+		&RedirectTargetList{},
+		&RedirectExemption{},
+		&RedirectExemptionList{},
+		&RuleQuota{},
+		&RuleQuotaList{},
 	)
 }
 
@@ -0,0 +1,134 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// RuleQuotaLimits are the limits of the number of rules, zero means no limit.
+// +k8s:deepcopy-gen=false
+type RuleQuotaLimits struct {
+	// NamespaceMax limits the Rules of a namespace without RuleQuota.
+	NamespaceMax int32
+	// ClusterMax limits all the Rules and ClusterRules, except the rules of
+	// ManagedNamespace.
+	ClusterMax int32
+	// ManagedNamespace is the namespace of the rules managed by the controller,
+	// its rules are limited by ManagedMax instead of the other limits.
+	ManagedNamespace string
+	ManagedMax       int32
+	// WarnPercent warns the creation when the usage of a limit reaches the
+	// percent, no warning when it's zero.
+	WarnPercent int32
+}
+
+// checkQuota checks the limits on creation of the rule, the counts are read
+// from the cache. Rules are admitted when they can't be counted.
+func (v *RuleValidator) checkQuota(ctx context.Context, obj RuleObject) (admission.Warnings, error) {
+	q := v.Quota
+	if q == nil || v.Reader == nil {
+		return nil, nil
+	}
+	ns := obj.GetNamespace()
+	var warnings admission.Warnings
+	// check checks the rule created in scope with used rules, max is
+	// negative when the scope isn't limited
+	check := func(scope string, used int, max int32) error {
+		if max < 0 {
+			return nil
+		}
+		if used+1 > int(max) {
+			return fmt.Errorf("rule quota of %s is exceeded, %d of %d rules are used", scope, used, max)
+		}
+		if q.WarnPercent > 0 && (used+1)*100 >= int(max)*int(q.WarnPercent) {
+			warnings = append(warnings, fmt.Sprintf("%s uses %d of %d rules of the quota", scope, used+1, max))
+		}
+		return nil
+	}
+
+	nsUsed := 0
+	if ns != "" {
+		n, err := v.count(ctx, &RuleList{}, client.InNamespace(ns))
+		if err != nil {
+			klog.Errorf("Failed to count rules of namespace %s: %s", ns, err)
+			return nil, nil
+		}
+		nsUsed = n
+	}
+	if ns != "" && ns == q.ManagedNamespace {
+		return warnings, check("namespace "+ns, nsUsed, limit(q.ManagedMax))
+	}
+	if ns != "" {
+		max, err := v.namespaceQuota(ctx, ns)
+		if err != nil {
+			klog.Errorf("Failed to list rule quotas of namespace %s: %s", ns, err)
+		} else if err := check("namespace "+ns, nsUsed, max); err != nil {
+			return warnings, err
+		}
+	}
+
+	if q.ClusterMax <= 0 {
+		return warnings, nil
+	}
+	rules, err := v.count(ctx, &RuleList{})
+	if err != nil {
+		klog.Errorf("Failed to count rules: %s", err)
+		return warnings, nil
+	}
+	clusterRules, err := v.count(ctx, &ClusterRuleList{})
+	if err != nil {
+		klog.Errorf("Failed to count cluster rules: %s", err)
+		return warnings, nil
+	}
+	// rules of the managed namespace don't take the cluster quota
+	managed := 0
+	if q.ManagedNamespace != "" {
+		if managed, err = v.count(ctx, &RuleList{}, client.InNamespace(q.ManagedNamespace)); err != nil {
+			klog.Errorf("Failed to count rules of namespace %s: %s", q.ManagedNamespace, err)
+			return warnings, nil
+		}
+	}
+	return warnings, check("the cluster", rules-managed+clusterRules, limit(q.ClusterMax))
+}
+
+// namespaceQuota returns the smallest RuleQuota of the namespace, or the
+// default limit when the namespace has no quota. A RuleQuota of zero rejects
+// any rule in the namespace.
+func (v *RuleValidator) namespaceQuota(ctx context.Context, ns string) (int32, error) {
+	quotas := &RuleQuotaList{}
+	if err := v.Reader.List(ctx, quotas, client.InNamespace(ns)); err != nil {
+		return 0, err
+	}
+	if len(quotas.Items) == 0 {
+		return limit(v.Quota.NamespaceMax), nil
+	}
+	max := quotas.Items[0].Spec.MaxRules
+	for _, q := range quotas.Items[1:] {
+		if q.Spec.MaxRules < max {
+			max = q.Spec.MaxRules
+		}
+	}
+	return max, nil
+}
+
+// limit returns -1 for the limits of zero which mean no limit.
+func limit(max int32) int32 {
+	if max <= 0 {
+		return -1
+	}
+	return max
+}
+
+func (v *RuleValidator) count(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (int, error) {
+	// the rules are only counted, skip copying them out of the cache
+	opts = append(opts, client.UnsafeDisableDeepCopy)
+	if err := v.Reader.List(ctx, list, opts...); err != nil {
+		return 0, err
+	}
+	return meta.LenList(list), nil
+}
@@ -0,0 +1,82 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRuleValidatorQuota(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	macs := 0
+	rule := func(ns, name string) *Rule {
+		macs++
+		return &Rule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:       RuleSpec{Direct: Both, Match: RuleMatch{Mac: fmt.Sprintf("00:11:22:33:44:%02x", macs)}},
+		}
+	}
+	var objs []client.Object
+	for i := 0; i < 3; i++ {
+		objs = append(objs, rule("default", fmt.Sprintf("r%d", i)), rule("tr-tower", fmt.Sprintf("vnic-%d-both", i)))
+	}
+	objs = append(objs,
+		rule("ns1", "r0"),
+		&RuleQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "q1"}, Spec: RuleQuotaSpec{MaxRules: 5}},
+		&RuleQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "q2"}, Spec: RuleQuotaSpec{MaxRules: 1}},
+		&RuleQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "q1"}, Spec: RuleQuotaSpec{MaxRules: 0}},
+		&ClusterRule{ObjectMeta: metav1.ObjectMeta{Name: "c1"}},
+	)
+	v := &RuleValidator{
+		Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithIndex(&Rule{}, RuleMacDirectIndex, RuleMacDirectIndexFunc).
+			WithIndex(&ClusterRule{}, RuleMacDirectIndex, RuleMacDirectIndexFunc).Build(),
+		Quota: &RuleQuotaLimits{
+			NamespaceMax:     4,
+			ClusterMax:       10,
+			ManagedNamespace: "tr-tower",
+			ManagedMax:       3,
+			WarnPercent:      80,
+		},
+	}
+	ctx := context.Background()
+
+	warnings, err := v.ValidateCreate(ctx, rule("default", "r3"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"namespace default uses 4 of 4 rules of the quota"}, []string(warnings))
+
+	_, err = v.ValidateCreate(ctx, rule("ns1", "r1"))
+	assert.ErrorContains(t, err, "rule quota of namespace ns1 is exceeded, 1 of 1 rules are used", "the smallest quota wins")
+	_, err = v.ValidateCreate(ctx, rule("ns2", "r1"))
+	assert.ErrorContains(t, err, "rule quota of namespace ns2 is exceeded, 0 of 0 rules are used")
+
+	_, err = v.ValidateCreate(ctx, rule("tr-tower", "vnic-3-both"))
+	assert.ErrorContains(t, err, "rule quota of namespace tr-tower is exceeded, 3 of 3 rules are used")
+	v.Quota.ManagedMax = 0
+	_, err = v.ValidateCreate(ctx, rule("tr-tower", "vnic-3-both"))
+	assert.NoError(t, err, "managed rules don't take the cluster quota")
+
+	v.Quota.ClusterMax = 6
+	warnings, err = v.ValidateCreate(ctx, rule("ns3", "r0"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"the cluster uses 6 of 6 rules of the quota"}, []string(warnings))
+	v.Quota.ClusterMax = 5
+	_, err = v.ValidateCreate(ctx, rule("ns3", "r0"))
+	assert.ErrorContains(t, err, "rule quota of the cluster is exceeded, 5 of 5 rules are used")
+
+	v.Quota.ClusterMax, v.Quota.WarnPercent = 0, 0
+	warnings, err = v.ValidateCreate(ctx, rule("ns3", "r0"))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	updated := rule("ns1", "r0")
+	_, err = v.ValidateUpdate(ctx, updated, updated)
+	assert.NoError(t, err, "quota is only checked on creation")
+}
@@ -0,0 +1,38 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=rulequotas,shortName=trq
+// +kubebuilder:printcolumn:name="maxrules",type="integer",JSONPath=".spec.maxRules"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RuleQuota limits the number of Rules in its namespace, the webhook rejects
+// creating Rules beyond the limit. The smallest limit wins when a namespace has
+// multiple quotas, namespaces without quota are limited by the controller
+// default.
+type RuleQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RuleQuotaSpec `json:"spec"`
+}
+
+type RuleQuotaSpec struct {
+	// MaxRules is the max number of Rules in the namespace.
+	// +kubebuilder:validation:Minimum=0
+	MaxRules int32 `json:"maxRules"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type RuleQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RuleQuota `json:"items"`
+}
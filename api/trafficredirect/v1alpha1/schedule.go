@@ -0,0 +1,116 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const scheduleTimeLayout = "15:04"
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// ExpireTime returns the time the rule is deleted at, nil when it never expires.
+// TTL counts from the creation of the rule.
+func (s *RuleSpec) ExpireTime(created metav1.Time) *time.Time {
+	switch {
+	case s.ExpiresAt != nil:
+		t := s.ExpiresAt.Time
+		return &t
+	case s.TTL != nil:
+		t := created.Add(s.TTL.Duration)
+		return &t
+	}
+	return nil
+}
+
+// Location returns the time zone of the schedule.
+func (s *RuleSchedule) Location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.TimeZone)
+}
+
+// Active returns whether now is in any window of the schedule, and the next
+// time the result may change.
+func (s *RuleSchedule) Active(now time.Time) (bool, time.Time, error) {
+	loc, err := s.Location()
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	var active bool
+	var next time.Time
+	// the window started yesterday may still be open, a window of each day
+	// in the next week must start after now
+	for offset := -1; offset <= 7; offset++ {
+		day := today.AddDate(0, 0, offset)
+		for i := range s.Windows {
+			start, end, err := s.Windows[i].bounds(day)
+			if err != nil {
+				return false, time.Time{}, err
+			}
+			if start.IsZero() {
+				continue
+			}
+			if !now.Before(start) && now.Before(end) {
+				active = true
+			}
+			for _, t := range []time.Time{start, end} {
+				if t.After(now) && (next.IsZero() || t.Before(next)) {
+					next = t
+				}
+			}
+		}
+	}
+	return active, next, nil
+}
+
+// bounds returns the start and end of the window started on the day, zero
+// when the window doesn't start on the day.
+func (w *ScheduleWindow) bounds(day time.Time) (time.Time, time.Time, error) {
+	if len(w.Days) != 0 {
+		on := false
+		for _, d := range w.Days {
+			if weekdays[d] == day.Weekday() {
+				on = true
+				break
+			}
+		}
+		if !on {
+			return time.Time{}, time.Time{}, nil
+		}
+	}
+	start, err := timeOfDay(day, w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := timeOfDay(day, w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !end.After(start) {
+		end, _ = timeOfDay(day.AddDate(0, 0, 1), w.End)
+	}
+	return start, end, nil
+}
+
+func timeOfDay(day time.Time, hhmm string) (time.Time, error) {
+	t, err := time.Parse(scheduleTimeLayout, hhmm)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s, must be HH:MM", hhmm)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
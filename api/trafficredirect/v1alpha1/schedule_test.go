@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRuleSchedule_Active(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	// 2026-10-12 is Monday
+	at := func(day, hour, min int, loc *time.Location) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, loc)
+	}
+	business := &RuleSchedule{Windows: []ScheduleWindow{{Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "09:00", End: "18:00"}}}
+	overnight := &RuleSchedule{Windows: []ScheduleWindow{{Days: []string{"Fri"}, Start: "22:00", End: "02:00"}}}
+
+	tests := []struct {
+		name       string
+		schedule   *RuleSchedule
+		now        time.Time
+		wantActive bool
+		wantNext   time.Time
+	}{
+		{
+			name:       "in business hours",
+			schedule:   business,
+			now:        at(12, 10, 0, time.UTC),
+			wantActive: true,
+			wantNext:   at(12, 18, 0, time.UTC),
+		},
+		{
+			name:       "at the end of the window",
+			schedule:   business,
+			now:        at(12, 18, 0, time.UTC),
+			wantActive: false,
+			wantNext:   at(13, 9, 0, time.UTC),
+		},
+		{
+			name:       "weekend",
+			schedule:   business,
+			now:        at(17, 10, 0, time.UTC),
+			wantActive: false,
+			wantNext:   at(19, 9, 0, time.UTC),
+		},
+		{
+			name:       "overnight window on the next day",
+			schedule:   overnight,
+			now:        at(17, 1, 0, time.UTC),
+			wantActive: true,
+			wantNext:   at(17, 2, 0, time.UTC),
+		},
+		{
+			name:       "overnight window not started",
+			schedule:   overnight,
+			now:        at(16, 21, 0, time.UTC),
+			wantActive: false,
+			wantNext:   at(16, 22, 0, time.UTC),
+		},
+		{
+			name: "time zone",
+			schedule: &RuleSchedule{
+				TimeZone: "Asia/Shanghai",
+				Windows:  []ScheduleWindow{{Start: "09:00", End: "18:00"}},
+			},
+			now:        at(12, 2, 0, time.UTC),
+			wantActive: true,
+			wantNext:   at(12, 18, 0, shanghai),
+		},
+		{
+			name:       "all day window",
+			schedule:   &RuleSchedule{Windows: []ScheduleWindow{{Start: "00:00", End: "00:00"}}},
+			now:        at(12, 10, 0, time.UTC),
+			wantActive: true,
+			wantNext:   at(13, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active, next, err := tt.schedule.Active(tt.now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantActive, active)
+			assert.True(t, tt.wantNext.Equal(next), "want next %s, got %s", tt.wantNext, next)
+		})
+	}
+
+	_, _, err = (&RuleSchedule{TimeZone: "Mars/Base", Windows: business.Windows}).Active(time.Now())
+	assert.Error(t, err)
+}
+
+func TestRuleSpec_ExpireTime(t *testing.T) {
+	created := metav1.NewTime(time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC))
+	assert.Nil(t, (&RuleSpec{}).ExpireTime(created))
+
+	expire := (&RuleSpec{TTL: &metav1.Duration{Duration: 2 * time.Hour}}).ExpireTime(created)
+	assert.Equal(t, created.Add(2*time.Hour), *expire)
+
+	at := metav1.NewTime(created.Add(time.Hour))
+	expire = (&RuleSpec{ExpiresAt: &at}).ExpireTime(created)
+	assert.Equal(t, at.Time, *expire)
+}
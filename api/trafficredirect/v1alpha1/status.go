@@ -0,0 +1,65 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RuleConditionAccepted is true when the rule spec passed validation.
+	RuleConditionAccepted = "Accepted"
+	// RuleConditionProgrammed is true when all reporting agents programmed the observed generation.
+	RuleConditionProgrammed = "Programmed"
+	// RuleConditionConflicted is true when the rule loses to another rule matching the same traffic.
+	RuleConditionConflicted = "Conflicted"
+	// RuleConditionTargetResolved is true when an endpoint is selected from the RedirectTarget of the action.
+	RuleConditionTargetResolved = "TargetResolved"
+	// RuleConditionSelected is true when match.selector is expanded into status.selectedMacs.
+	RuleConditionSelected = "Selected"
+	// RuleConditionActive is true when the rule isn't suspended and the time is in the schedule of the
+	// rule, agents only program active rules.
+	RuleConditionActive = "Active"
+)
+
+const (
+	ReasonValid               = "Valid"
+	ReasonInvalid             = "Invalid"
+	ReasonPending             = "Pending"
+	ReasonProgrammed          = "Programmed"
+	ReasonProgrammingFailed   = "ProgrammingFailed"
+	ReasonResolved            = "Resolved"
+	ReasonTargetNotFound      = "TargetNotFound"
+	ReasonNoAvailableEndpoint = "NoAvailableEndpoint"
+	ReasonNoConflict          = "NoConflict"
+	ReasonOverridden          = "Overridden"
+	ReasonSelected            = "Selected"
+	ReasonSelectFailed        = "SelectFailed"
+	ReasonNoSchedule          = "NoSchedule"
+	ReasonInSchedule          = "InSchedule"
+	ReasonOutOfSchedule       = "OutOfSchedule"
+	ReasonSuspended           = "Suspended"
+)
+
+// SetNodeStatus adds or replaces the programming state reported by a node.
+// It is used by data-plane agents before updating the status subresource.
+func (s *RuleStatus) SetNodeStatus(n RuleNodeStatus) {
+	if n.LastUpdateTime.IsZero() {
+		n.LastUpdateTime = metav1.Now()
+	}
+	for i := range s.Nodes {
+		if s.Nodes[i].Node == n.Node {
+			s.Nodes[i] = n
+			return
+		}
+	}
+	s.Nodes = append(s.Nodes, n)
+}
+
+// RemoveNodeStatus removes the programming state reported by a node.
+func (s *RuleStatus) RemoveNodeStatus(node string) {
+	for i := range s.Nodes {
+		if s.Nodes[i].Node == node {
+			s.Nodes = append(s.Nodes[:i], s.Nodes[i+1:]...)
+			return
+		}
+	}
+}
@@ -1,63 +1,610 @@
 package v1alpha1
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"regexp"
 	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-var _ admission.Validator = &Rule{}
+const (
+	// AnnotationAllowZeroMacMask allows mac/mask of zero mask in the match of
+	// the rule when it's "true", such a mac/mask matches any mac.
+	AnnotationAllowZeroMacMask = "tr.everoute.io/allow-zero-mac-mask"
+	// AnnotationBreakGlass allows anyone to change or delete a managed rule
+	// when it's "true". It's set on the rule before deleting it.
+	AnnotationBreakGlass = "tr.everoute.io/break-glass"
+	// LabelManagedBy marks the rule managed by a controller, the rule is only
+	// changed by the ManagerUsername of the webhook.
+	LabelManagedBy = "tr.everoute.io/managed-by"
+
+	// kubeSystemUserPrefix is the prefix of the users of the kube controllers,
+	// e.g. the namespace controller and the garbage collector.
+	kubeSystemUserPrefix = "system:serviceaccount:kube-system:"
+)
+
+// RuleValidator is the validating webhook of Rule and ClusterRule, it checks
+// the rule against the existing rules and RedirectExemptions read from the
+// cache of the manager.
+// +k8s:deepcopy-gen=false
+type RuleValidator struct {
+	Reader client.Reader
+	// RejectDuplicates rejects a rule duplicating an existing rule, the rule
+	// is admitted with a warning otherwise.
+	RejectDuplicates bool
+	// ExemptNamespaces are the namespaces of rules not checked for duplicates,
+	// e.g. the rules managed by the vnic controller.
+	ExemptNamespaces []string
+	// ManagerUsername is the user of the controller managing the rules of
+	// LabelManagedBy, managed rules are not protected when it's empty.
+	ManagerUsername string
+	// TowerVMs checks option.towerVM of rules when it's set.
+	TowerVMs TowerVMReader
+	// TowerVMStrict rejects the rule failing the tower vm check, the rule is
+	// admitted with warnings otherwise.
+	TowerVMStrict bool
+	// Quota limits the number of rules on creation, rules are not limited
+	// when it's nil.
+	Quota *RuleQuotaLimits
+	// Clock checks expiresAt of rules, it's the real clock when nil.
+	Clock clock.PassiveClock
+}
+
+// TowerVMReader reads the nic macs of tower vms.
+type TowerVMReader interface {
+	// VMMacs returns the lower case nic macs of the vm, exists is false when
+	// the vm isn't found.
+	VMMacs(ctx context.Context, id string) (macs []string, exists bool, err error)
+}
+
+var _ admission.CustomValidator = &RuleValidator{}
 var _ admission.Defaulter = &Rule{}
+var _ admission.Defaulter = &ClusterRule{}
 
-func (r *Rule) SetupWebhookWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+// NewRuleValidator indexes the rules by RuleMacDirectIndex in the cache of the
+// manager and returns the validator reading the cache.
+func NewRuleValidator(mgr ctrl.Manager, managerUsername string, rejectDuplicates bool, exemptNamespaces ...string) (*RuleValidator, error) {
+	for _, obj := range []client.Object{&Rule{}, &ClusterRule{}} {
+		err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, RuleMacDirectIndex, RuleMacDirectIndexFunc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &RuleValidator{
+		Reader:           mgr.GetClient(),
+		RejectDuplicates: rejectDuplicates,
+		ExemptNamespaces: exemptNamespaces,
+		ManagerUsername:  managerUsername,
+	}, nil
 }
 
-func (r *Rule) ValidateCreate() (admission.Warnings, error) {
+func (v *RuleValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(RuleObject)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
 	klog.Infof("Start to validate create rule %v", r)
-	return nil, r.validateSpec()
+	// others can't create rules they can't change afterwards
+	if err := v.checkManager(ctx, r, r, "create"); err != nil {
+		return nil, err
+	}
+	if err := v.validateExpiresAt(nil, r); err != nil {
+		return nil, err
+	}
+	warnings, err := v.validate(ctx, r)
+	if err != nil {
+		return warnings, err
+	}
+	quotaWarnings, err := v.checkQuota(ctx, r)
+	return append(warnings, quotaWarnings...), err
 }
 
-func (r *Rule) ValidateUpdate(runtime.Object) (admission.Warnings, error) {
+func (v *RuleValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(RuleObject)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", newObj)
+	}
 	klog.Infof("Start to validate update rule %v", r)
-	return nil, r.validateSpec()
+	if old, ok := oldObj.(RuleObject); ok {
+		if managedChanged(old, r) {
+			if err := v.checkManager(ctx, old, r, "update"); err != nil {
+				return nil, err
+			}
+			// the managed-by label can't be added by others either
+			if err := v.checkManager(ctx, r, r, "update"); err != nil {
+				return nil, err
+			}
+		}
+		if err := r.GetRuleSpec().ValidateImmutable(old.GetRuleSpec()); err != nil {
+			return nil, err
+		}
+		if err := v.validateExpiresAt(old, r); err != nil {
+			return nil, err
+		}
+	}
+	return v.validate(ctx, r)
+}
+
+// validateExpiresAt rejects expiresAt in the past on create, and on update
+// when it changes. The rule expired already is updated until it's deleted.
+func (v *RuleValidator) validateExpiresAt(old, r RuleObject) error {
+	expiresAt := r.GetRuleSpec().ExpiresAt
+	if expiresAt == nil {
+		return nil
+	}
+	if old != nil && equality.Semantic.DeepEqual(old.GetRuleSpec().ExpiresAt, expiresAt) {
+		return nil
+	}
+	var clk clock.PassiveClock = clock.RealClock{}
+	if v.Clock != nil {
+		clk = v.Clock
+	}
+	if !expiresAt.Time.After(clk.Now()) {
+		return fmt.Errorf("expiresAt %s is in the past", expiresAt.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (v *RuleValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(RuleObject)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+	return nil, v.checkManager(ctx, r, r, "delete")
+}
+
+// checkManager only allows the manager to change the rule managed by it,
+// others must set the break glass annotation on the rule first.
+func (v *RuleValidator) checkManager(ctx context.Context, old, r RuleObject, op string) error {
+	manager, managed := old.GetLabels()[LabelManagedBy]
+	if !managed || v.ManagerUsername == "" {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	user := req.UserInfo.Username
+	if user == v.ManagerUsername {
+		return nil
+	}
+	// managed rules are deleted with their namespace or owner
+	if op == "delete" && (strings.HasPrefix(user, kubeSystemUserPrefix) || r.GetDeletionTimestamp() != nil) {
+		return nil
+	}
+	if r.GetAnnotations()[AnnotationBreakGlass] == "true" {
+		klog.Warningf("User %s %s rule %s managed by %s with break glass", user, op, RuleRef(r), manager)
+		return nil
+	}
+	return fmt.Errorf("rule is managed by %s, user %s can't %s it unless annotation %s is set to true", manager, user, op, AnnotationBreakGlass)
+}
+
+// managedChanged reports whether the update changes the rule managed by the
+// controller. Operators may suspend a managed rule and change its annotations.
+func managedChanged(old, r RuleObject) bool {
+	if !equality.Semantic.DeepEqual(old.GetLabels(), r.GetLabels()) {
+		return true
+	}
+	oldSpec := old.GetRuleSpec().DeepCopy()
+	oldSpec.Suspend = r.GetRuleSpec().Suspend
+	return !equality.Semantic.DeepEqual(oldSpec, r.GetRuleSpec())
+}
+
+func (v *RuleValidator) validate(ctx context.Context, r RuleObject) (admission.Warnings, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	warnings := v.exemptionWarnings(ctx, r)
+
+	problems, err := v.checkTowerVM(ctx, r)
+	if err != nil {
+		// tower outage doesn't block the admission
+		warnings = append(warnings, fmt.Sprintf("failed to check tower vm: %s", err))
+	}
+	if len(problems) != 0 {
+		if v.TowerVMStrict {
+			return warnings, errors.New(strings.Join(problems, ", "))
+		}
+		warnings = append(warnings, problems...)
+	}
+
+	// rules are admitted when the existing rules can't be read
+	dups, err := v.duplicates(ctx, r)
+	if err != nil {
+		klog.Errorf("Failed to list rules of the same mac and direct: %s", err)
+		return warnings, nil
+	}
+	if len(dups) != 0 {
+		msg := fmt.Sprintf("rule duplicates the mac and direct of rules %s with the same priority, set a different priority to order them", strings.Join(dups, ", "))
+		if v.RejectDuplicates {
+			return warnings, errors.New(msg)
+		}
+		warnings = append(warnings, msg)
+	}
+	return warnings, nil
+}
+
+// duplicates returns the other rules of the same mac and direct which overlap
+// the rule with the same priority, neither of them takes precedence by intent.
+func (v *RuleValidator) duplicates(ctx context.Context, obj RuleObject) ([]string, error) {
+	exempt := sets.New(v.ExemptNamespaces...)
+	if v.Reader == nil || exempt.Has(obj.GetNamespace()) {
+		return nil, nil
+	}
+	rule := obj.AsRule()
+	dups := sets.New[string]()
+	check := func(peer RuleObject) {
+		if exempt.Has(peer.GetNamespace()) || peer.GetDeletionTimestamp() != nil {
+			return
+		}
+		if peer.GetNamespace() == obj.GetNamespace() && peer.GetName() == obj.GetName() {
+			return
+		}
+		p := peer.AsRule()
+		if p.Spec.Priority == rule.Spec.Priority && p.Overlaps(rule) {
+			dups.Insert(RuleRef(peer))
+		}
+	}
+
+	for _, key := range RuleMacDirectIndexFunc(obj) {
+		opt := client.MatchingFields{RuleMacDirectIndex: key}
+		list := &RuleList{}
+		if err := v.Reader.List(ctx, list, opt); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			check(&list.Items[i])
+		}
+		clusterList := &ClusterRuleList{}
+		if err := v.Reader.List(ctx, clusterList, opt); err != nil {
+			return nil, err
+		}
+		for i := range clusterList.Items {
+			check(&clusterList.Items[i])
+		}
+	}
+	return sets.List(dups), nil
+}
+
+// checkTowerVM returns the problems of option.towerVM of the rule, the vm
+// must exist and the workload macs of the rule must be its nic macs.
+func (v *RuleValidator) checkTowerVM(ctx context.Context, obj RuleObject) ([]string, error) {
+	opt := obj.GetRuleSpec().Option
+	if v.TowerVMs == nil || opt == nil || opt.TowerVM == "" {
+		return nil, nil
+	}
+	macs, exists, err := v.TowerVMs.VMMacs(ctx, opt.TowerVM)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []string{fmt.Sprintf("tower vm %s is not found", opt.TowerVM)}, nil
+	}
+	nicMacs := sets.New(macs...)
+	checked := sets.New[string]()
+	var problems []string
+	for _, d := range obj.AsRule().Expand() {
+		mac := workloadMac(d)
+		if mac == "" || checked.Has(mac) {
+			continue
+		}
+		checked.Insert(mac)
+		if !nicMacs.Has(mac) {
+			problems = append(problems, fmt.Sprintf("mac %s is not a nic of tower vm %s", mac, opt.TowerVM))
+		}
+	}
+	return problems, nil
+}
+
+func (r *Rule) SetupWebhookWithManager(mgr ctrl.Manager, v *RuleValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).WithValidator(v).Complete()
+}
+
+// Validate checks the rule spec the same way as the validating webhook.
+func (r *Rule) Validate() error {
+	return r.validateSpec()
 }
-func (r *Rule) ValidateDelete() (admission.Warnings, error) { return nil, nil }
 
 func (r *Rule) validateSpec() error {
-	if r.Spec.Direct != Egress && r.Spec.Direct != Ingress {
-		return fmt.Errorf("direct must set ingress or egress")
+	return r.Spec.validate(newValidateOptions(r))
+}
+
+func (r *Rule) Default() {
+	klog.Infof("Start to modify rule %v", r)
+	r.Spec.Default()
+}
+
+func (r *ClusterRule) SetupWebhookWithManager(mgr ctrl.Manager, v *RuleValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).WithValidator(v).Complete()
+}
+
+// Validate checks the cluster rule spec the same way as the validating webhook.
+func (r *ClusterRule) Validate() error {
+	if err := r.Spec.validate(newValidateOptions(r)); err != nil {
+		return err
+	}
+	// RedirectTarget is namespaced, a cluster rule has no namespace to find it
+	if r.Spec.Action != nil && r.Spec.Action.Target.RedirectTarget != "" {
+		return fmt.Errorf("action target redirectTarget is not supported by cluster rule")
+	}
+	return nil
+}
+
+func (r *ClusterRule) Default() {
+	klog.Infof("Start to modify cluster rule %v", r)
+	r.Spec.Default()
+}
+
+// exemptionWarnings warns the exempt traffic matched by the rule, the traffic
+// is not redirected by the rule. Rules are admitted when exemptions can't be read.
+func (v *RuleValidator) exemptionWarnings(ctx context.Context, obj RuleObject) admission.Warnings {
+	if v.Reader == nil {
+		return nil
+	}
+	exemptions := &RedirectExemptionList{}
+	if err := v.Reader.List(ctx, exemptions); err != nil {
+		klog.Errorf("Failed to list redirect exemptions: %s", err)
+		return nil
+	}
+	rule := obj.AsRule()
+	matches := []*RuleMatch{&rule.Spec.Match}
+	if rule.Spec.Match.Selector == nil {
+		matches = matches[:0]
+		for _, e := range rule.Expand() {
+			matches = append(matches, &e.Spec.Match)
+		}
+	}
+
+	var warnings admission.Warnings
+	seen := sets.New[string]()
+	for i := range exemptions.Items {
+		e := &exemptions.Items[i]
+		for _, m := range matches {
+			for _, caught := range e.Spec.Catches(m) {
+				w := fmt.Sprintf("rule matches %s exempted by RedirectExemption %s, the traffic is not redirected", caught, e.Name)
+				if !seen.Has(w) {
+					seen.Insert(w)
+					warnings = append(warnings, w)
+				}
+			}
+		}
 	}
-	if r.Spec.Match.DstMac == "" && r.Spec.Match.SrcMac == "" {
+	return warnings
+}
+
+// validateOptions relaxes the validation by annotations of the rule.
+type validateOptions struct {
+	allowZeroMacMask bool
+}
+
+func newValidateOptions(obj metav1.Object) validateOptions {
+	return validateOptions{
+		allowZeroMacMask: obj.GetAnnotations()[AnnotationAllowZeroMacMask] == "true",
+	}
+}
+
+// validate checks the spec, it's shared by Rule and ClusterRule.
+func (s *RuleSpec) validate(opts validateOptions) error {
+	if s.Direct != Egress && s.Direct != Ingress && s.Direct != Both {
+		return fmt.Errorf("direct must set ingress, egress or both")
+	}
+	m := &s.Match
+	if m.DstMac == "" && m.SrcMac == "" && m.Mac == "" && len(m.SrcMacs) == 0 && len(m.DstMacs) == 0 && m.Selector == nil {
 		return fmt.Errorf("must set rule match")
 	}
-	if r.Spec.Match.DstMac != "" {
-		err := r.validateMac(r.Spec.Match.DstMac)
+	if s.Direct == Both {
+		if m.SrcMac != "" || m.DstMac != "" || len(m.SrcMacs) != 0 || len(m.DstMacs) != 0 || (m.Mac == "" && m.Selector == nil) {
+			return fmt.Errorf("direct both must set mac instead of srcMac and dstMac")
+		}
+		if m.Mac != "" {
+			if err := s.validateMacMatch(m.Mac, opts); err != nil {
+				return err
+			}
+		}
+	} else if m.Mac != "" {
+		return fmt.Errorf("mac is only for direct both, use srcMac or dstMac instead")
+	}
+	if err := s.validateSelector(); err != nil {
+		return err
+	}
+	if m.DstMac != "" {
+		err := s.validateMacMatch(m.DstMac, opts)
 		if err != nil {
 			return err
 		}
 	}
-	if r.Spec.Match.SrcMac != "" {
-		err := r.validateMac(r.Spec.Match.SrcMac)
+	if m.SrcMac != "" {
+		err := s.validateMacMatch(m.SrcMac, opts)
 		if err != nil {
 			return err
 		}
 	}
+	if err := s.validateMacList("srcMacs", m.SrcMac, m.SrcMacs, opts); err != nil {
+		return err
+	}
+	if err := s.validateMacList("dstMacs", m.DstMac, m.DstMacs, opts); err != nil {
+		return err
+	}
 
-	if r.Spec.Option != nil {
-		if r.Spec.Option.TowerVM == "" {
+	if err := s.validateL2Match(); err != nil {
+		return err
+	}
+
+	if err := s.validateIPMatch(); err != nil {
+		return err
+	}
+
+	if err := s.validateFailurePolicy(); err != nil {
+		return err
+	}
+
+	if err := s.validateAction(); err != nil {
+		return err
+	}
+
+	if err := s.validateSampling(); err != nil {
+		return err
+	}
+
+	if err := s.validateLifetime(); err != nil {
+		return err
+	}
+
+	if s.Option != nil {
+		if s.Option.TowerVM == "" {
 			return fmt.Errorf("must set option with tower vmid when option is set")
 		}
 	}
 	return nil
 }
 
-func (r *Rule) validateMac(m string) error {
+func (s *RuleSpec) validateSelector() error {
+	sel := s.Match.Selector
+	if sel == nil {
+		return nil
+	}
+	if len(sel.TowerVMs) == 0 && len(sel.TowerLabels) == 0 && sel.LabelSelector == nil {
+		return fmt.Errorf("selector must set towerVMs, towerLabels or labelSelector")
+	}
+	// the selected macs take the place of the workload mac of the direct
+	switch {
+	case s.Direct == Egress && s.Match.SrcMac != "":
+		return fmt.Errorf("selector can't be set with srcMac for direct egress")
+	case s.Direct == Egress && len(s.Match.SrcMacs) != 0:
+		return fmt.Errorf("selector can't be set with srcMacs for direct egress")
+	case s.Direct == Ingress && s.Match.DstMac != "":
+		return fmt.Errorf("selector can't be set with dstMac for direct ingress")
+	case s.Direct == Ingress && len(s.Match.DstMacs) != 0:
+		return fmt.Errorf("selector can't be set with dstMacs for direct ingress")
+	case s.Direct == Both && s.Match.Mac != "":
+		return fmt.Errorf("selector can't be set with mac for direct both")
+	}
+	for _, vm := range sel.TowerVMs {
+		if vm == "" {
+			return fmt.Errorf("selector tower vm id must not be empty")
+		}
+	}
+	for _, l := range sel.TowerLabels {
+		if l.Key == "" {
+			return fmt.Errorf("selector tower label key must not be empty")
+		}
+	}
+	if sel.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(sel.LabelSelector); err != nil {
+			return fmt.Errorf("selector labelSelector is invalid: %s", err)
+		}
+	}
+	return nil
+}
+
+// ValidateImmutable rejects changing the direct and the macs matched by the
+// rule, agents can't apply them in place, the rule must be deleted and created
+// again. The macs are compared in the canonical notation. The selector is
+// mutable, the selected macs follow it like the membership changes.
+func (s *RuleSpec) ValidateImmutable(old *RuleSpec) error {
+	var fields []string
+	if s.Direct != old.Direct {
+		fields = append(fields, "direct")
+	}
+	for _, f := range []struct {
+		name     string
+		old, new []string
+	}{
+		{"match.mac", []string{old.Match.Mac}, []string{s.Match.Mac}},
+		{"match.srcMac", []string{old.Match.SrcMac}, []string{s.Match.SrcMac}},
+		{"match.dstMac", []string{old.Match.DstMac}, []string{s.Match.DstMac}},
+		{"match.srcMacs", old.Match.SrcMacs, s.Match.SrcMacs},
+		{"match.dstMacs", old.Match.DstMacs, s.Match.DstMacs},
+	} {
+		if !macsEqual(f.old, f.new) {
+			fields = append(fields, f.name)
+		}
+	}
+	if len(fields) != 0 {
+		return fmt.Errorf("rule %s can't be updated, delete and create the rule instead", strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+func macsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if normalizeMac(a[i]) != normalizeMac(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateFailurePolicy allows the empty policy of the rules created before
+// the field, agents take it as open.
+func (s *RuleSpec) validateFailurePolicy() error {
+	switch s.FailurePolicy {
+	case "", FailOpen, FailClosed:
+	default:
+		return fmt.Errorf("failurePolicy must set open or closed")
+	}
+	return nil
+}
+
+func (s *RuleSpec) validateAction() error {
+	a := s.Action
+	if a == nil {
+		return nil
+	}
+	switch a.Mode {
+	case ActionRedirect, ActionMirror, ActionDropCopy:
+	default:
+		return fmt.Errorf("action mode must set redirect, mirror or drop-copy")
+	}
+	targets := 0
+	for _, t := range []string{a.Target.Mac, a.Target.Port, a.Target.RedirectTarget} {
+		if t != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return fmt.Errorf("action target must set exactly one of mac, port and redirectTarget")
+	}
+	if a.Target.Mac != "" {
+		return s.validateMac(a.Target.Mac)
+	}
+	return nil
+}
+
+// validateMac checks a single mac of a nic, the broadcast and zero macs are
+// not macs of any nic.
+func (s *RuleSpec) validateMac(m string) error {
+	if err := validateMacFormat(m); err != nil {
+		return err
+	}
+	switch m {
+	case "ff:ff:ff:ff:ff:ff":
+		return fmt.Errorf("mac %s is the broadcast mac, it's not a nic mac", m)
+	case "00:00:00:00:00:00":
+		return fmt.Errorf("mac %s is the zero mac, it's not a nic mac", m)
+	}
+	return nil
+}
+
+// validateMacFormat checks the mac is in the canonical form, the mutating
+// webhook rewrites other notations into it.
+func validateMacFormat(m string) error {
 	regex := `^([0-9a-f]{2}:){5}[0-9a-f]{2}$`
 	matched, err := regexp.MatchString(regex, m)
 	if err != nil {
@@ -69,8 +616,247 @@ func (r *Rule) validateMac(m string) error {
 	return nil
 }
 
-func (r *Rule) Default() {
-	klog.Infof("Start to modify rule %v", r)
-	r.Spec.Match.SrcMac = strings.ToLower(r.Spec.Match.SrcMac)
-	r.Spec.Match.DstMac = strings.ToLower(r.Spec.Match.DstMac)
+// validateMacMatch checks a mac or mac/mask to match, a zero mask matches any
+// mac and is rejected unless it's allowed by annotation.
+func (s *RuleSpec) validateMacMatch(m string, opts validateOptions) error {
+	mac, mask, masked := strings.Cut(m, "/")
+	if !masked {
+		return s.validateMac(mac)
+	}
+	if err := validateMacFormat(mac); err != nil {
+		return err
+	}
+	if err := validateMacFormat(mask); err != nil {
+		return fmt.Errorf("mask of %s is invalid: %s", m, err)
+	}
+	if mask == "00:00:00:00:00:00" && !opts.allowZeroMacMask {
+		return fmt.Errorf("mac %s has zero mask which matches any mac, set annotation %s to allow it", m, AnnotationAllowZeroMacMask)
+	}
+	return nil
+}
+
+func (s *RuleSpec) validateMacList(field, single string, macs []string, opts validateOptions) error {
+	if len(macs) == 0 {
+		return nil
+	}
+	if single != "" {
+		return fmt.Errorf("%s can't be set with %s", field, strings.TrimSuffix(field, "s"))
+	}
+	seen := make(map[string]bool, len(macs))
+	for _, m := range macs {
+		if err := s.validateMacMatch(m, opts); err != nil {
+			return err
+		}
+		if seen[m] {
+			return fmt.Errorf("mac %s is duplicated in %s", m, field)
+		}
+		seen[m] = true
+	}
+	return nil
+}
+
+func (s *RuleSpec) validateIPMatch() error {
+	m := &s.Match
+	var family int
+	for _, c := range []string{m.SrcCIDR, m.DstCIDR} {
+		if c == "" {
+			continue
+		}
+		ip, _, err := net.ParseCIDR(c)
+		if err != nil {
+			return fmt.Errorf("cidr %s is invalid: %s", c, err)
+		}
+		f := ipFamily(ip)
+		if family != 0 && family != f {
+			return fmt.Errorf("srcCIDR %s and dstCIDR %s must be the same ip family", m.SrcCIDR, m.DstCIDR)
+		}
+		family = f
+	}
+
+	switch m.Protocol {
+	case "", ProtocolTCP, ProtocolUDP, ProtocolSCTP:
+	case ProtocolICMP:
+		if family == 6 {
+			return fmt.Errorf("protocol ICMP can't match ipv6 cidr")
+		}
+		family = 4
+	case ProtocolICMPv6:
+		if family == 4 {
+			return fmt.Errorf("protocol ICMPv6 can't match ipv4 cidr")
+		}
+		family = 6
+	default:
+		return fmt.Errorf("protocol %s is unsupported", m.Protocol)
+	}
+
+	if m.EtherType != "" && (family != 0 || m.Protocol != "") {
+		if m.EtherType != EtherTypeIPv4 && m.EtherType != EtherTypeIPv6 {
+			return fmt.Errorf("ethertype %s can't match ip cidr or protocol", m.EtherType)
+		}
+		if (m.EtherType == EtherTypeIPv4 && family == 6) || (m.EtherType == EtherTypeIPv6 && family == 4) {
+			return fmt.Errorf("ethertype %s doesn't match the ip family of cidr or protocol", m.EtherType)
+		}
+	}
+
+	if m.SrcPort == nil && m.DstPort == nil {
+		return nil
+	}
+	if m.Protocol != ProtocolTCP && m.Protocol != ProtocolUDP && m.Protocol != ProtocolSCTP {
+		return fmt.Errorf("port match requires protocol TCP, UDP or SCTP")
+	}
+	if err := validatePortRange(m.SrcPort); err != nil {
+		return err
+	}
+	return validatePortRange(m.DstPort)
+}
+
+func (s *RuleSpec) validateL2Match() error {
+	m := &s.Match
+	if m.VlanID != nil && (*m.VlanID < 1 || *m.VlanID > 4094) {
+		return fmt.Errorf("vlan %d is out of range 1-4094", *m.VlanID)
+	}
+	if m.VlanIDEnd != nil {
+		if m.VlanID == nil {
+			return fmt.Errorf("vlanIDEnd must be set with vlanID")
+		}
+		if *m.VlanIDEnd < *m.VlanID || *m.VlanIDEnd > 4094 {
+			return fmt.Errorf("vlan range %d-%d is invalid", *m.VlanID, *m.VlanIDEnd)
+		}
+	}
+	if m.EtherType != "" {
+		if _, err := m.EtherType.Value(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RuleSpec) validateSampling() error {
+	if s.SampleRate == nil && s.TruncateBytes == nil {
+		return nil
+	}
+	if s.Action == nil || s.Action.Mode != ActionMirror {
+		return fmt.Errorf("sampleRate and truncateBytes are only for action mode mirror")
+	}
+	if s.SampleRate != nil && (*s.SampleRate < 1 || *s.SampleRate > MaxSampleRate) {
+		return fmt.Errorf("sampleRate %d is out of range 1-%d", *s.SampleRate, MaxSampleRate)
+	}
+	if s.TruncateBytes != nil && (*s.TruncateBytes < MinTruncateBytes || *s.TruncateBytes > MaxTruncateBytes) {
+		return fmt.Errorf("truncateBytes %d is out of range %d-%d", *s.TruncateBytes, MinTruncateBytes, MaxTruncateBytes)
+	}
+	return nil
+}
+
+func (s *RuleSpec) validateLifetime() error {
+	if s.ExpiresAt != nil && s.TTL != nil {
+		return fmt.Errorf("expiresAt and ttl can't be set together")
+	}
+	if s.TTL != nil && s.TTL.Duration <= 0 {
+		return fmt.Errorf("ttl must be positive")
+	}
+	if s.Schedule == nil {
+		return nil
+	}
+	if len(s.Schedule.Windows) == 0 {
+		return fmt.Errorf("schedule must set windows")
+	}
+	if _, err := s.Schedule.Location(); err != nil {
+		return fmt.Errorf("invalid schedule timeZone %s: %s", s.Schedule.TimeZone, err)
+	}
+	for _, w := range s.Schedule.Windows {
+		for _, d := range w.Days {
+			if _, ok := weekdays[d]; !ok {
+				return fmt.Errorf("invalid schedule window day %s, must be one of Mon, Tue, Wed, Thu, Fri, Sat and Sun", d)
+			}
+		}
+		if _, err := time.Parse(scheduleTimeLayout, w.Start); err != nil {
+			return fmt.Errorf("invalid schedule window start %s, must be HH:MM", w.Start)
+		}
+		if _, err := time.Parse(scheduleTimeLayout, w.End); err != nil {
+			return fmt.Errorf("invalid schedule window end %s, must be HH:MM", w.End)
+		}
+	}
+	return nil
+}
+
+func validatePortRange(p *PortRange) error {
+	if p == nil {
+		return nil
+	}
+	if p.Begin < 1 || p.Begin > 65535 {
+		return fmt.Errorf("port %d is out of range 1-65535", p.Begin)
+	}
+	if p.End != 0 && (p.End < p.Begin || p.End > 65535) {
+		return fmt.Errorf("port range %d-%d is invalid", p.Begin, p.End)
+	}
+	return nil
+}
+
+func ipFamily(ip net.IP) int {
+	if ip.To4() != nil {
+		return 4
+	}
+	return 6
+}
+
+// Default sets the defaults and normalizes the spec, it's shared by Rule and ClusterRule.
+func (s *RuleSpec) Default() {
+	s.Match.SrcMac = normalizeMac(s.Match.SrcMac)
+	s.Match.DstMac = normalizeMac(s.Match.DstMac)
+	s.Match.Mac = normalizeMac(s.Match.Mac)
+	for _, macs := range [][]string{s.Match.SrcMacs, s.Match.DstMacs} {
+		for i := range macs {
+			macs[i] = normalizeMac(macs[i])
+		}
+	}
+	s.Match.SrcCIDR = normalizeCIDR(s.Match.SrcCIDR)
+	s.Match.DstCIDR = normalizeCIDR(s.Match.DstCIDR)
+	s.Match.Protocol = normalizeProtocol(s.Match.Protocol)
+	s.Match.EtherType = s.Match.EtherType.Normalize()
+	for _, p := range []*PortRange{s.Match.SrcPort, s.Match.DstPort} {
+		if p != nil && p.End == 0 {
+			p.End = p.Begin
+		}
+	}
+	if s.FailurePolicy == "" {
+		s.FailurePolicy = FailOpen
+	}
+	if a := s.Action; a != nil {
+		if a.Mode == "" {
+			a.Mode = ActionRedirect
+		}
+		a.Target.Mac = normalizeMac(a.Target.Mac)
+	}
+}
+
+// normalizeCIDR converts a single ip to host cidr and clears the host bits,
+// invalid value is kept as it is and rejected by validation.
+func normalizeCIDR(c string) string {
+	if c == "" {
+		return c
+	}
+	if !strings.Contains(c, "/") {
+		ip := net.ParseIP(c)
+		if ip == nil {
+			return c
+		}
+		if ipFamily(ip) == 4 {
+			return ip.String() + "/32"
+		}
+		return ip.String() + "/128"
+	}
+	_, ipNet, err := net.ParseCIDR(c)
+	if err != nil {
+		return c
+	}
+	return ipNet.String()
+}
+
+func normalizeProtocol(p Protocol) Protocol {
+	for _, known := range []Protocol{ProtocolTCP, ProtocolUDP, ProtocolSCTP, ProtocolICMP, ProtocolICMPv6} {
+		if strings.EqualFold(string(p), string(known)) {
+			return known
+		}
+	}
+	return p
 }
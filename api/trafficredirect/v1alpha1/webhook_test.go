@@ -1,9 +1,20 @@
 package v1alpha1
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 func TestRule_validateSpec(t *testing.T) {
@@ -19,7 +30,7 @@ func TestRule_validateSpec(t *testing.T) {
 				Direct: "test",
 			}},
 			wantErr:   true,
-			errorText: "must set ingress or egress",
+			errorText: "must set ingress, egress or both",
 		},
 		{
 			name: "missing rule match",
@@ -31,73 +42,732 @@ func TestRule_validateSpec(t *testing.T) {
 			wantErr:   true,
 			errorText: "must set rule match",
 		},
+		{
+			name: "both with mac",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Both,
+				Match:  RuleMatch{Mac: "00:11:22:33:44:55"},
+			}},
+		},
+		{
+			name: "both with srcMac",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Both,
+				Match:  RuleMatch{Mac: "00:11:22:33:44:55", SrcMac: "00:11:22:33:44:55"},
+			}},
+			wantErr:   true,
+			errorText: "direct both must set mac instead of srcMac and dstMac",
+		},
+		{
+			name: "both without mac",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Both,
+				Match:  RuleMatch{DstMac: "00:11:22:33:44:55"},
+			}},
+			wantErr:   true,
+			errorText: "direct both must set mac instead of srcMac and dstMac",
+		},
+		{
+			name: "both with invalid mac",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Both,
+				Match:  RuleMatch{Mac: "invalid"},
+			}},
+			wantErr:   true,
+			errorText: "mac invalid is invalid",
+		},
+		{
+			name: "egress with selector",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Egress,
+				Match:  RuleMatch{DstMac: "00:11:22:33:44:55", Selector: &VMSelector{TowerVMs: []string{"vm1"}}},
+			}},
+		},
+		{
+			name: "both with selector",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Both,
+				Match:  RuleMatch{Selector: &VMSelector{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}},
+			}},
+		},
+		{
+			name: "empty selector",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Ingress,
+				Match:  RuleMatch{Selector: &VMSelector{}},
+			}},
+			wantErr:   true,
+			errorText: "selector must set towerVMs, towerLabels or labelSelector",
+		},
+		{
+			name: "selector with srcMac for egress",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Egress,
+				Match:  RuleMatch{SrcMac: "00:11:22:33:44:55", Selector: &VMSelector{TowerVMs: []string{"vm1"}}},
+			}},
+			wantErr:   true,
+			errorText: "selector can't be set with srcMac for direct egress",
+		},
+		{
+			name: "selector with empty tower label key",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Ingress,
+				Match:  RuleMatch{Selector: &VMSelector{TowerLabels: []TowerLabel{{Value: "prod"}}}},
+			}},
+			wantErr:   true,
+			errorText: "selector tower label key must not be empty",
+		},
+		{
+			name: "selector with invalid label selector",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Ingress,
+				Match: RuleMatch{Selector: &VMSelector{LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Unknown"}},
+				}}},
+			}},
+			wantErr:   true,
+			errorText: "selector labelSelector is invalid",
+		},
+		{
+			name: "mac with ingress",
+			rule: Rule{Spec: RuleSpec{
+				Direct: Ingress,
+				Match:  RuleMatch{Mac: "00:11:22:33:44:55"},
+			}},
+			wantErr:   true,
+			errorText: "mac is only for direct both",
+		},
 		{
 			name: "invalid dst mac",
 			rule: Rule{
 				Spec: RuleSpec{
-					Direct: Ingress,
-					Match:  RuleMatch{DstMac: "invalid"},
+					Direct: Ingress,
+					Match:  RuleMatch{DstMac: "invalid"},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac invalid is invalid",
+		},
+		{
+			name: "invalid src mac",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Ingress,
+					Match:  RuleMatch{SrcMac: "zz:zz", DstMac: "12:13:13:13:13:5a"},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac zz:zz is invalid",
+		},
+		{
+			name: "incomplete tower option",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{DstMac: "00:11:22:33:44:55"},
+					Option: &Option{
+						TowerVM: "",
+					},
+				},
+			},
+			wantErr:   true,
+			errorText: "must set option with tower vmid when option is set",
+		},
+		{
+			name: "valid rule with dst mac",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Ingress,
+					Match:  RuleMatch{DstMac: "00:11:22:33:44:55"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid dst mac with -",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Ingress,
+					Match:  RuleMatch{DstMac: "00-11-22-33-44-55"},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac 00-11-22-33-44-55 is invalid",
+		},
+		{
+			name: "invalid dst mac with A-F",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Ingress,
+					Match:  RuleMatch{DstMac: "00:F5:22:33:44:55"},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac 00:F5:22:33:44:55 is invalid",
+		},
+		{
+			name: "broadcast dst mac",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Ingress,
+					Match:  RuleMatch{DstMac: "ff:ff:ff:ff:ff:ff"},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac ff:ff:ff:ff:ff:ff is the broadcast mac, it's not a nic mac",
+		},
+		{
+			name: "zero src mac",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "00:00:00:00:00:00"},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac 00:00:00:00:00:00 is the zero mac, it's not a nic mac",
+		},
+		{
+			name: "zero target mac",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action: &RuleAction{Mode: ActionRedirect, Target: RedirectTargetRef{Mac: "00:00:00:00:00:00"}},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac 00:00:00:00:00:00 is the zero mac",
+		},
+		{
+			name: "multicast mac/mask with zero address",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Ingress,
+					Match:  RuleMatch{DstMac: "00:00:00:00:00:00/01:00:00:00:00:00"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid src cidr",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", SrcCIDR: "10.0.0.0/33"},
+				},
+			},
+			wantErr:   true,
+			errorText: "cidr 10.0.0.0/33 is invalid",
+		},
+		{
+			name: "mixed ip family",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", SrcCIDR: "10.0.0.0/24", DstCIDR: "fd00::/64"},
+				},
+			},
+			wantErr:   true,
+			errorText: "must be the same ip family",
+		},
+		{
+			name: "icmp with ipv6 cidr",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", DstCIDR: "fd00::/64", Protocol: ProtocolICMP},
+				},
+			},
+			wantErr:   true,
+			errorText: "protocol ICMP can't match ipv6 cidr",
+		},
+		{
+			name: "unsupported protocol",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", Protocol: "GRE"},
+				},
+			},
+			wantErr:   true,
+			errorText: "protocol GRE is unsupported",
+		},
+		{
+			name: "port without protocol",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", DstPort: &PortRange{Begin: 80, End: 80}},
+				},
+			},
+			wantErr:   true,
+			errorText: "port match requires protocol TCP, UDP or SCTP",
+		},
+		{
+			name: "port out of range",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", Protocol: ProtocolTCP, DstPort: &PortRange{Begin: 0}},
+				},
+			},
+			wantErr:   true,
+			errorText: "port 0 is out of range",
+		},
+		{
+			name: "reversed port range",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", Protocol: ProtocolUDP, SrcPort: &PortRange{Begin: 100, End: 10}},
+				},
+			},
+			wantErr:   true,
+			errorText: "port range 100-10 is invalid",
+		},
+		{
+			name: "valid rule with l3 and l4 match",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match: RuleMatch{
+						SrcMac:   "aa:bb:cc:dd:ee:ff",
+						SrcCIDR:  "fd00::/64",
+						DstCIDR:  "fd01::1/128",
+						Protocol: ProtocolTCP,
+						DstPort:  &PortRange{Begin: 8000, End: 8080},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "vlan out of range",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", VlanID: pointer.Int32(4095)},
+				},
+			},
+			wantErr:   true,
+			errorText: "vlan 4095 is out of range",
+		},
+		{
+			name: "vlan end without begin",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", VlanIDEnd: pointer.Int32(10)},
+				},
+			},
+			wantErr:   true,
+			errorText: "vlanIDEnd must be set with vlanID",
+		},
+		{
+			name: "reversed vlan range",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", VlanID: pointer.Int32(20), VlanIDEnd: pointer.Int32(10)},
+				},
+			},
+			wantErr:   true,
+			errorText: "vlan range 20-10 is invalid",
+		},
+		{
+			name: "unknown ethertype",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", EtherType: "ipx"},
+				},
+			},
+			wantErr:   true,
+			errorText: "ethertype ipx is unknown",
+		},
+		{
+			name: "ethertype value is frame length",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", EtherType: "0x0100"},
+				},
+			},
+			wantErr:   true,
+			errorText: "less than 0x0600",
+		},
+		{
+			name: "non ip ethertype with cidr",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", EtherType: EtherTypeARP, DstCIDR: "10.0.0.0/8"},
+				},
+			},
+			wantErr:   true,
+			errorText: "ethertype arp can't match ip cidr or protocol",
+		},
+		{
+			name: "ethertype mismatch ip family",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", EtherType: EtherTypeIPv6, Protocol: ProtocolICMP},
+				},
+			},
+			wantErr:   true,
+			errorText: "ethertype ipv6 doesn't match the ip family",
+		},
+		{
+			name: "valid rule with vlan range and ethertype",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Ingress,
+					Match: RuleMatch{
+						DstMac:    "aa:bb:cc:dd:ee:ff",
+						VlanID:    pointer.Int32(100),
+						VlanIDEnd: pointer.Int32(200),
+						EtherType: EtherTypeIPv4,
+						DstCIDR:   "10.0.0.0/8",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid action mode",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action: &RuleAction{Mode: "copy", Target: RedirectTargetRef{Port: "dpi0"}},
+				},
+			},
+			wantErr:   true,
+			errorText: "action mode must set redirect, mirror or drop-copy",
+		},
+		{
+			name: "action without target",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action: &RuleAction{Mode: ActionRedirect},
+				},
+			},
+			wantErr:   true,
+			errorText: "action target must set exactly one of mac, port and redirectTarget",
+		},
+		{
+			name: "action with both target mac and port",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action: &RuleAction{Mode: ActionRedirect, Target: RedirectTargetRef{Mac: "00:11:22:33:44:55", Port: "dpi0"}},
+				},
+			},
+			wantErr:   true,
+			errorText: "action target must set exactly one of mac, port and redirectTarget",
+		},
+		{
+			name: "action with invalid target mac",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action: &RuleAction{Mode: ActionRedirect, Target: RedirectTargetRef{Mac: "00:11"}},
+				},
+			},
+			wantErr:   true,
+			errorText: "mac 00:11 is invalid",
+		},
+		{
+			name: "valid rule with mirror action",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action: &RuleAction{Mode: ActionMirror, Target: RedirectTargetRef{Mac: "00:11:22:33:44:55"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid failure policy",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:        Egress,
+					Match:         RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					FailurePolicy: "drop",
+				},
+			},
+			wantErr:   true,
+			errorText: "failurePolicy must set open or closed",
+		},
+		{
+			name: "expiresAt with ttl",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:    Egress,
+					Match:     RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					ExpiresAt: &metav1.Time{},
+					TTL:       &metav1.Duration{Duration: time.Hour},
+				},
+			},
+			wantErr:   true,
+			errorText: "expiresAt and ttl can't be set together",
+		},
+		{
+			name: "negative ttl",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					TTL:    &metav1.Duration{Duration: -time.Hour},
+				},
+			},
+			wantErr:   true,
+			errorText: "ttl must be positive",
+		},
+		{
+			name: "schedule without windows",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:   Egress,
+					Match:    RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Schedule: &RuleSchedule{},
 				},
 			},
 			wantErr:   true,
-			errorText: "mac invalid is invalid",
+			errorText: "schedule must set windows",
 		},
 		{
-			name: "invalid src mac",
+			name: "schedule with invalid time zone",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:   Egress,
+					Match:    RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Schedule: &RuleSchedule{TimeZone: "Mars/Base", Windows: []ScheduleWindow{{Start: "09:00", End: "18:00"}}},
+				},
+			},
+			wantErr:   true,
+			errorText: "invalid schedule timeZone Mars/Base",
+		},
+		{
+			name: "schedule with invalid day",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:   Egress,
+					Match:    RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Schedule: &RuleSchedule{Windows: []ScheduleWindow{{Days: []string{"Monday"}, Start: "09:00", End: "18:00"}}},
+				},
+			},
+			wantErr:   true,
+			errorText: "invalid schedule window day Monday",
+		},
+		{
+			name: "schedule with invalid end",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:   Egress,
+					Match:    RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Schedule: &RuleSchedule{Windows: []ScheduleWindow{{Start: "09:00", End: "24:00"}}},
+				},
+			},
+			wantErr:   true,
+			errorText: "invalid schedule window end 24:00",
+		},
+		{
+			name: "valid rule with schedule and ttl",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:   Egress,
+					Match:    RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Schedule: &RuleSchedule{TimeZone: "Asia/Shanghai", Windows: []ScheduleWindow{{Days: []string{"Mon"}, Start: "22:00", End: "02:00"}}},
+					TTL:      &metav1.Duration{Duration: 2 * time.Hour},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "sample rate in redirect mode",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:     Egress,
+					Match:      RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action:     &RuleAction{Mode: ActionRedirect, Target: RedirectTargetRef{Port: "dpi0"}},
+					SampleRate: pointer.Int32(10),
+				},
+			},
+			wantErr:   true,
+			errorText: "sampleRate and truncateBytes are only for action mode mirror",
+		},
+		{
+			name: "truncate bytes without action",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:        Egress,
+					Match:         RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					TruncateBytes: pointer.Int32(128),
+				},
+			},
+			wantErr:   true,
+			errorText: "sampleRate and truncateBytes are only for action mode mirror",
+		},
+		{
+			name: "zero sample rate",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:     Egress,
+					Match:      RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action:     &RuleAction{Mode: ActionMirror, Target: RedirectTargetRef{Port: "dpi0"}},
+					SampleRate: pointer.Int32(0),
+				},
+			},
+			wantErr:   true,
+			errorText: "sampleRate 0 is out of range 1-65535",
+		},
+		{
+			name: "truncate bytes too small",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:        Egress,
+					Match:         RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action:        &RuleAction{Mode: ActionMirror, Target: RedirectTargetRef{Port: "dpi0"}},
+					TruncateBytes: pointer.Int32(14),
+				},
+			},
+			wantErr:   true,
+			errorText: "truncateBytes 14 is out of range 64-65535",
+		},
+		{
+			name: "valid mirror rule with sampling and truncation",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct:        Egress,
+					Match:         RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff"},
+					Action:        &RuleAction{Mode: ActionMirror, Target: RedirectTargetRef{Port: "dpi0"}},
+					SampleRate:    pointer.Int32(100),
+					TruncateBytes: pointer.Int32(128),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid rule with oui mask",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid mac mask",
 			rule: Rule{
 				Spec: RuleSpec{
 					Direct: Ingress,
-					Match:  RuleMatch{SrcMac: "zz:zz", DstMac: "12:13:13:13:13:5a"},
+					Match:  RuleMatch{DstMac: "00:50:56:00:00:00/ff:ff:ff"},
 				},
 			},
 			wantErr:   true,
-			errorText: "mac zz:zz is invalid",
+			errorText: "mask of 00:50:56:00:00:00/ff:ff:ff is invalid",
 		},
 		{
-			name: "incomplete tower option",
+			name: "zero mac mask",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Both,
+					Match:  RuleMatch{Mac: "00:00:00:00:00:00/00:00:00:00:00:00"},
+				},
+			},
+			wantErr:   true,
+			errorText: "has zero mask which matches any mac",
+		},
+		{
+			name: "zero mac mask allowed by annotation",
+			rule: Rule{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{AnnotationAllowZeroMacMask: "true"}},
+				Spec: RuleSpec{
+					Direct: Both,
+					Match:  RuleMatch{Mac: "00:00:00:00:00:00/00:00:00:00:00:00"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid rule with mac lists",
 			rule: Rule{
 				Spec: RuleSpec{
 					Direct: Egress,
-					Match:  RuleMatch{DstMac: "00:11:22:33:44:55"},
-					Option: &Option{
-						TowerVM: "",
+					Match: RuleMatch{
+						SrcMacs: []string{"aa:bb:cc:dd:ee:ff", "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+						DstMacs: []string{"11:22:33:44:55:66"},
 					},
 				},
 			},
+			wantErr: false,
+		},
+		{
+			name: "srcMac with srcMacs",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match:  RuleMatch{SrcMac: "aa:bb:cc:dd:ee:ff", SrcMacs: []string{"11:22:33:44:55:66"}},
+				},
+			},
 			wantErr:   true,
-			errorText: "must set option with tower vmid when option is set",
+			errorText: "srcMacs can't be set with srcMac",
 		},
 		{
-			name: "valid rule with dst mac",
+			name: "duplicated mac in dstMacs",
 			rule: Rule{
 				Spec: RuleSpec{
 					Direct: Ingress,
-					Match:  RuleMatch{DstMac: "00:11:22:33:44:55"},
+					Match:  RuleMatch{DstMacs: []string{"11:22:33:44:55:66", "11:22:33:44:55:66"}},
 				},
 			},
-			wantErr: false,
+			wantErr:   true,
+			errorText: "mac 11:22:33:44:55:66 is duplicated in dstMacs",
 		},
 		{
-			name: "invalid dst mac with -",
+			name: "invalid mac in srcMacs",
 			rule: Rule{
 				Spec: RuleSpec{
-					Direct: Ingress,
-					Match:  RuleMatch{DstMac: "00-11-22-33-44-55"},
+					Direct: Egress,
+					Match:  RuleMatch{SrcMacs: []string{"11:22:33:44:55"}},
 				},
 			},
 			wantErr:   true,
-			errorText: "mac 00-11-22-33-44-55 is invalid",
+			errorText: "mac 11:22:33:44:55 is invalid",
 		},
 		{
-			name: "invalid dst mac with A-F",
+			name: "direct both with mac lists",
 			rule: Rule{
 				Spec: RuleSpec{
-					Direct: Ingress,
-					Match:  RuleMatch{DstMac: "00:F5:22:33:44:55"},
+					Direct: Both,
+					Match:  RuleMatch{Mac: "aa:bb:cc:dd:ee:ff", SrcMacs: []string{"11:22:33:44:55:66"}},
 				},
 			},
 			wantErr:   true,
-			errorText: "mac 00:F5:22:33:44:55 is invalid",
+			errorText: "direct both must set mac instead of srcMac and dstMac",
+		},
+		{
+			name: "selector with srcMacs for egress",
+			rule: Rule{
+				Spec: RuleSpec{
+					Direct: Egress,
+					Match: RuleMatch{
+						SrcMacs:  []string{"11:22:33:44:55:66"},
+						Selector: &VMSelector{TowerVMs: []string{"vm1"}},
+					},
+				},
+			},
+			wantErr:   true,
+			errorText: "selector can't be set with srcMacs for direct egress",
 		},
 		{
 			name: "valid rule with src mac and tower option",
@@ -133,6 +803,7 @@ func TestRuleDefault(t *testing.T) {
 			Match: RuleMatch{
 				SrcMac: "AA:BB:CC:D5:Ee:FF",
 				DstMac: "11:22:33:44:55:ee",
+				Mac:    "00:0A:0B:0C:0D:0E",
 			},
 		},
 	}
@@ -141,4 +812,430 @@ func TestRuleDefault(t *testing.T) {
 
 	assert.Equal(t, "aa:bb:cc:d5:ee:ff", r.Spec.Match.SrcMac)
 	assert.Equal(t, "11:22:33:44:55:ee", r.Spec.Match.DstMac) // already lowercase
+	assert.Equal(t, "00:0a:0b:0c:0d:0e", r.Spec.Match.Mac)
+}
+
+func TestRuleDefaultMacMask(t *testing.T) {
+	r := &Rule{
+		Spec: RuleSpec{
+			Match: RuleMatch{
+				SrcMac:  "00:50:56:AB:CD:EF/FF:FF:FF:00:00:00",
+				DstMac:  "00:11:22:33:44:55/ff:ff:ff:ff:ff:ff",
+				SrcMacs: []string{"AA:BB:CC:DD:EE:FF", "00:0C:29:12:34:56/ff:ff:ff:00:00:00"},
+			},
+		},
+	}
+	r.Default()
+	assert.Equal(t, "00:50:56:00:00:00/ff:ff:ff:00:00:00", r.Spec.Match.SrcMac)
+	assert.Equal(t, "00:11:22:33:44:55", r.Spec.Match.DstMac)
+	assert.Equal(t, []string{"aa:bb:cc:dd:ee:ff", "00:0c:29:00:00:00/ff:ff:ff:00:00:00"}, r.Spec.Match.SrcMacs)
+}
+
+func TestRuleDefaultMacNotations(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "aa:bb:cc:dd:ee:ff", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "AA:BB:CC:DD:EE:FF", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "AA-BB-CC-DD-EE-FF", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "aabb.ccdd.eeff", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "AABBCCDDEEFF", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "00-50-56-AB-CD-EF/FF-FF-FF-00-00-00", want: "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+		{in: "005056abcdef/ffffff000000", want: "00:50:56:00:00:00/ff:ff:ff:00:00:00"},
+		{in: "aabbccddeeff/ffff.ffff.ffff", want: "aa:bb:cc:dd:ee:ff"},
+		// invalid values are kept and rejected by validation
+		{in: "aabbccddeefg", want: "aabbccddeefg"},
+		{in: "aa:bb:cc:dd:ee", want: "aa:bb:cc:dd:ee"},
+		{in: "00:00:5e:10:00:00:00:01", want: "00:00:5e:10:00:00:00:01"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r := &Rule{Spec: RuleSpec{
+				Match:  RuleMatch{SrcMac: tt.in, DstMacs: []string{tt.in}},
+				Action: &RuleAction{Target: RedirectTargetRef{Mac: tt.in}},
+			}}
+			r.Default()
+			assert.Equal(t, tt.want, r.Spec.Match.SrcMac)
+			assert.Equal(t, []string{tt.want}, r.Spec.Match.DstMacs)
+			assert.Equal(t, tt.want, r.Spec.Action.Target.Mac)
+		})
+	}
+}
+
+func TestParseMac(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "aa:bb:cc:dd:ee:ff", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "AA-BB-CC-DD-EE-FF", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "aabb.ccdd.eeff", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "aabbccddeeff", want: "aa:bb:cc:dd:ee:ff"},
+		{in: "aabbccddeeffgg", wantErr: true},
+		{in: "aabbccddeezz", wantErr: true},
+		{in: "02:00:5e:10:00:00:00:01", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			mac, err := ParseMac(tt.in)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "is invalid")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, mac.String())
+		})
+	}
+}
+
+func TestRuleDefaultIPMatch(t *testing.T) {
+	r := &Rule{
+		Spec: RuleSpec{
+			Match: RuleMatch{
+				SrcCIDR:  "10.0.0.5/24",
+				DstCIDR:  "FD00::1",
+				Protocol: "tcp",
+				SrcPort:  &PortRange{Begin: 80},
+				DstPort:  &PortRange{Begin: 8000, End: 8080},
+			},
+		},
+	}
+	r.Default()
+	assert.Equal(t, "10.0.0.0/24", r.Spec.Match.SrcCIDR)
+	assert.Equal(t, "fd00::1/128", r.Spec.Match.DstCIDR)
+	assert.Equal(t, ProtocolTCP, r.Spec.Match.Protocol)
+	assert.Equal(t, PortRange{Begin: 80, End: 80}, *r.Spec.Match.SrcPort)
+	assert.Equal(t, PortRange{Begin: 8000, End: 8080}, *r.Spec.Match.DstPort)
+}
+
+func TestEtherTypeNormalize(t *testing.T) {
+	tests := map[EtherType]EtherType{
+		"IPv4":   EtherTypeIPv4,
+		"0x86DD": EtherTypeIPv6,
+		"0x88B5": "0x88b5",
+		"ipx":    "ipx",
+		"":       "",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, in.Normalize(), "normalize %s", in)
+	}
+}
+
+func TestRuleDefaultAction(t *testing.T) {
+	r := &Rule{
+		Spec: RuleSpec{
+			Action: &RuleAction{Target: RedirectTargetRef{Mac: "00:AA:22:33:44:55"}},
+		},
+	}
+	r.Default()
+	assert.Equal(t, ActionRedirect, r.Spec.Action.Mode)
+	assert.Equal(t, "00:aa:22:33:44:55", r.Spec.Action.Target.Mac)
+
+	r = &Rule{}
+	r.Default()
+	assert.Nil(t, r.Spec.Action)
+}
+
+func TestRuleDefaultFailurePolicy(t *testing.T) {
+	r := &Rule{}
+	r.Default()
+	assert.Equal(t, FailOpen, r.Spec.FailurePolicy)
+
+	r = &Rule{Spec: RuleSpec{FailurePolicy: FailClosed}}
+	r.Default()
+	assert.Equal(t, FailClosed, r.Spec.FailurePolicy)
+}
+
+func TestClusterRuleValidate(t *testing.T) {
+	r := &ClusterRule{Spec: RuleSpec{
+		Direct: Egress,
+		Match:  RuleMatch{SrcMac: "00:11:22:33:44:55"},
+		Action: &RuleAction{Target: RedirectTargetRef{Mac: "00:11:22:33:44:66"}},
+	}}
+	r.Default()
+	assert.NoError(t, r.Validate())
+	_, err := (&RuleValidator{}).ValidateCreate(context.Background(), r)
+	assert.NoError(t, err)
+
+	r.Spec.Action.Target = RedirectTargetRef{RedirectTarget: "target"}
+	err = r.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "action target redirectTarget is not supported by cluster rule")
+
+	r.Spec.Action = nil
+	r.Spec.Match.SrcMac = "invalid"
+	assert.Error(t, r.Validate())
+}
+
+func TestClusterRuleDefault(t *testing.T) {
+	r := &ClusterRule{Spec: RuleSpec{Direct: Both, Match: RuleMatch{Mac: "00:AA:22:33:44:55"}}}
+	r.Default()
+	assert.Equal(t, "00:aa:22:33:44:55", r.Spec.Match.Mac)
+}
+
+func TestRuleSpecSampling(t *testing.T) {
+	s := &RuleSpec{}
+	assert.Equal(t, uint16(65535), s.SampleProbability())
+	assert.Equal(t, uint16(0), s.SnapLength())
+
+	s = &RuleSpec{SampleRate: pointer.Int32(5), TruncateBytes: pointer.Int32(128)}
+	assert.Equal(t, uint16(13107), s.SampleProbability())
+	assert.Equal(t, uint16(128), s.SnapLength())
+}
+
+func TestRuleValidatorDuplicates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	egress := func(ns, name, mac string) *Rule {
+		return &Rule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:       RuleSpec{Direct: Egress, Match: RuleMatch{SrcMac: mac}},
+		}
+	}
+	v := &RuleValidator{
+		Reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			egress("default", "r1", "00:11:22:33:44:01"),
+			egress("tr-tower", "vnic-1-egress", "00:11:22:33:44:02"),
+			&ClusterRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+				Spec:       RuleSpec{Direct: Ingress, Match: RuleMatch{DstMac: "00:11:22:33:44:03"}},
+			},
+		).WithIndex(&Rule{}, RuleMacDirectIndex, RuleMacDirectIndexFunc).
+			WithIndex(&ClusterRule{}, RuleMacDirectIndex, RuleMacDirectIndexFunc).Build(),
+		RejectDuplicates: true,
+		ExemptNamespaces: []string{"tr-tower"},
+	}
+	ctx := context.Background()
+
+	_, err := v.ValidateCreate(ctx, egress("default", "r2", "00:11:22:33:44:01"))
+	assert.ErrorContains(t, err, "rule duplicates the mac and direct of rules default/r1 with the same priority")
+
+	both := &Rule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "r3"},
+		Spec:       RuleSpec{Direct: Both, Match: RuleMatch{Mac: "00:11:22:33:44:03"}},
+	}
+	_, err = v.ValidateCreate(ctx, both)
+	assert.ErrorContains(t, err, "clusterrule/c1")
+
+	prioritized := egress("default", "r2", "00:11:22:33:44:01")
+	prioritized.Spec.Priority = 10
+	_, err = v.ValidateCreate(ctx, prioritized)
+	assert.NoError(t, err)
+
+	ported := egress("default", "r2", "00:11:22:33:44:01")
+	ported.Spec.Match.Protocol, ported.Spec.Match.DstCIDR = ProtocolTCP, "10.0.0.0/8"
+	_, err = v.ValidateCreate(ctx, ported)
+	assert.ErrorContains(t, err, "default/r1", "overlapping match is a duplicate")
+	_, err = v.ValidateCreate(ctx, egress("default", "r2", "00:11:22:33:44:04"))
+	assert.NoError(t, err)
+
+	_, err = v.ValidateUpdate(ctx, nil, egress("default", "r1", "00:11:22:33:44:01"))
+	assert.NoError(t, err, "the rule itself is not a duplicate")
+
+	_, err = v.ValidateCreate(ctx, egress("default", "r2", "00:11:22:33:44:02"))
+	assert.NoError(t, err, "rules of exempt namespaces are not duplicates")
+	_, err = v.ValidateCreate(ctx, egress("tr-tower", "vnic-2-egress", "00:11:22:33:44:01"))
+	assert.NoError(t, err, "rules of exempt namespaces are not checked")
+
+	v.RejectDuplicates = false
+	warnings, err := v.ValidateCreate(ctx, egress("default", "r2", "00:11:22:33:44:01"))
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "default/r1")
+}
+
+func TestRuleValidatorManagedRule(t *testing.T) {
+	v := &RuleValidator{ManagerUsername: "system:serviceaccount:kube-system:tr-controller"}
+	as := func(user string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: user}},
+		})
+	}
+	managed := &Rule{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "tr-tower",
+			Name:      "vnic-1-both",
+			Labels:    map[string]string{LabelManagedBy: "vnic-controller"},
+		},
+		Spec: RuleSpec{Direct: Both, Match: RuleMatch{Mac: "00:11:22:33:44:01"}},
+	}
+
+	changed := managed.DeepCopy()
+	changed.Spec.Priority = 10
+	_, err := v.ValidateUpdate(as("admin"), managed, changed)
+	assert.ErrorContains(t, err, "rule is managed by vnic-controller, user admin can't update it")
+	_, err = v.ValidateUpdate(as(v.ManagerUsername), managed, changed)
+	assert.NoError(t, err)
+
+	unlabeled := managed.DeepCopy()
+	unlabeled.Labels = nil
+	_, err = v.ValidateUpdate(as("admin"), managed, unlabeled)
+	assert.Error(t, err, "the managed-by label can't be removed")
+
+	suspended := managed.DeepCopy()
+	suspended.Spec.Suspend = true
+	suspended.Annotations = map[string]string{"note": "maintenance"}
+	_, err = v.ValidateUpdate(as("admin"), managed, suspended)
+	assert.NoError(t, err, "operators may suspend a managed rule")
+
+	_, err = v.ValidateDelete(as("admin"), managed)
+	assert.ErrorContains(t, err, "user admin can't delete it")
+	_, err = v.ValidateDelete(as(v.ManagerUsername), managed)
+	assert.NoError(t, err)
+
+	broken := changed.DeepCopy()
+	broken.Annotations = map[string]string{AnnotationBreakGlass: "true"}
+	_, err = v.ValidateUpdate(as("admin"), managed, broken)
+	assert.NoError(t, err)
+	_, err = v.ValidateDelete(as("admin"), broken)
+	assert.NoError(t, err)
+
+	user := managed.DeepCopy()
+	user.Labels = nil
+	_, err = v.ValidateDelete(as("admin"), user)
+	assert.NoError(t, err, "rules not managed are not protected")
+
+	_, err = v.ValidateCreate(as("admin"), managed)
+	assert.ErrorContains(t, err, "user admin can't create it")
+	_, err = v.ValidateCreate(as(v.ManagerUsername), managed)
+	assert.NoError(t, err)
+	_, err = v.ValidateUpdate(as("admin"), user, managed)
+	assert.ErrorContains(t, err, "user admin can't update it", "the managed-by label can't be added")
+
+	_, err = v.ValidateDelete(as("system:serviceaccount:kube-system:namespace-controller"), managed)
+	assert.NoError(t, err, "managed rules are deleted with the namespace")
+	_, err = v.ValidateDelete(as("system:serviceaccount:kube-system:generic-garbage-collector"), managed)
+	assert.NoError(t, err, "managed rules are deleted with the owner")
+	_, err = v.ValidateUpdate(as("system:serviceaccount:kube-system:generic-garbage-collector"), managed, changed)
+	assert.ErrorContains(t, err, "can't update it", "kube controllers only delete managed rules")
+	deleting := managed.DeepCopy()
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	_, err = v.ValidateDelete(as("admin"), deleting)
+	assert.NoError(t, err, "rules being deleted are deleted again")
+
+	_, err = (&RuleValidator{}).ValidateDelete(as("admin"), managed)
+	assert.NoError(t, err, "managed rules are not protected without the manager")
+}
+
+type fakeTowerVMs map[string][]string
+
+func (f fakeTowerVMs) VMMacs(_ context.Context, id string) ([]string, bool, error) {
+	if id == "unavailable" {
+		return nil, false, fmt.Errorf("tower unavailable")
+	}
+	macs, ok := f[id]
+	return macs, ok, nil
+}
+
+func TestRuleValidatorTowerVM(t *testing.T) {
+	v := &RuleValidator{TowerVMs: fakeTowerVMs{"vm1": {"00:11:22:33:44:01", "00:11:22:33:44:02"}}}
+	ctx := context.Background()
+	rule := func(vm, mac string) *Rule {
+		return &Rule{Spec: RuleSpec{
+			Direct: Both,
+			Match:  RuleMatch{Mac: mac},
+			Option: &Option{TowerVM: vm},
+		}}
+	}
+
+	warnings, err := v.ValidateCreate(ctx, rule("vm1", "00:11:22:33:44:02"))
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	warnings, err = v.ValidateCreate(ctx, rule("vm1", "00:11:22:33:44:03"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mac 00:11:22:33:44:03 is not a nic of tower vm vm1"}, []string(warnings))
+
+	warnings, err = v.ValidateCreate(ctx, rule("vm2", "00:11:22:33:44:01"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tower vm vm2 is not found"}, []string(warnings))
+
+	v.TowerVMStrict = true
+	_, err = v.ValidateCreate(ctx, rule("vm1", "00:11:22:33:44:03"))
+	assert.ErrorContains(t, err, "mac 00:11:22:33:44:03 is not a nic of tower vm vm1")
+
+	warnings, err = v.ValidateCreate(ctx, rule("unavailable", "00:11:22:33:44:01"))
+	assert.NoError(t, err, "tower errors don't block the admission")
+	assert.Equal(t, []string{"failed to check tower vm: tower unavailable"}, []string(warnings))
+
+	noOption := rule("", "00:11:22:33:44:03")
+	noOption.Spec.Option = nil
+	_, err = v.ValidateCreate(ctx, noOption)
+	assert.NoError(t, err)
+}
+
+func TestRuleValidatorImmutable(t *testing.T) {
+	v := &RuleValidator{}
+	ctx := context.Background()
+	old := &Rule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "r1"},
+		Spec: RuleSpec{
+			Direct: Egress,
+			Match:  RuleMatch{SrcMac: "00:11:22:33:44:01", DstMacs: []string{"00:50:56:00:00:00/ff:ff:ff:00:00:00"}},
+			Option: &Option{TowerVM: "vm1"},
+		},
+	}
+
+	mutable := old.DeepCopy()
+	mutable.Spec.Priority = 10
+	mutable.Spec.Suspend = true
+	mutable.Spec.Option.TowerVM = "vm2"
+	mutable.Spec.Match.Protocol = ProtocolTCP
+	legacy := old.DeepCopy()
+	legacy.Spec.Match.SrcMac = "0011.2233.4401"
+	_, err := v.ValidateUpdate(ctx, legacy, mutable)
+	assert.NoError(t, err, "options, priority, suspend and mac notations are mutable")
+
+	direct := old.DeepCopy()
+	direct.Spec.Direct = Ingress
+	direct.Spec.Match = RuleMatch{DstMac: "00:11:22:33:44:01"}
+	_, err = v.ValidateUpdate(ctx, old, direct)
+	assert.EqualError(t, err, "rule direct, match.srcMac, match.dstMac, match.dstMacs can't be updated, delete and create the rule instead")
+
+	macs := old.DeepCopy()
+	macs.Spec.Match.DstMacs = append(macs.Spec.Match.DstMacs, "00:11:22:33:44:02")
+	_, err = v.ValidateUpdate(ctx, old, macs)
+	assert.ErrorContains(t, err, "rule match.dstMacs can't be updated")
+
+	selector := &ClusterRule{Spec: RuleSpec{Direct: Both, Match: RuleMatch{Selector: &VMSelector{TowerVMs: []string{"vm1"}}}}}
+	changed := selector.DeepCopy()
+	changed.Spec.Match.Selector.TowerVMs = []string{"vm2"}
+	_, err = v.ValidateUpdate(ctx, selector, changed)
+	assert.NoError(t, err, "selector is mutable")
+}
+
+func TestRuleValidatorExpiresAt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := &RuleValidator{Clock: testingclock.NewFakePassiveClock(now)}
+	ctx := context.Background()
+	newRule := func(expiresAt time.Time) *Rule {
+		return &Rule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "r1"},
+			Spec: RuleSpec{
+				Direct:    Egress,
+				Match:     RuleMatch{SrcMac: "00:11:22:33:44:01"},
+				ExpiresAt: &metav1.Time{Time: expiresAt},
+			},
+		}
+	}
+
+	_, err := v.ValidateCreate(ctx, newRule(now.Add(time.Hour)))
+	assert.NoError(t, err)
+	_, err = v.ValidateCreate(ctx, newRule(now.Add(-time.Hour)))
+	assert.EqualError(t, err, "expiresAt 2023-12-31T23:00:00Z is in the past")
+
+	expired := newRule(now.Add(-time.Hour))
+	suspended := expired.DeepCopy()
+	suspended.Spec.Suspend = true
+	_, err = v.ValidateUpdate(ctx, expired, suspended)
+	assert.NoError(t, err, "the expired rule is updated until it's deleted")
+
+	_, err = v.ValidateUpdate(ctx, newRule(now.Add(time.Hour)), expired)
+	assert.ErrorContains(t, err, "is in the past")
+	_, err = v.ValidateUpdate(ctx, expired, newRule(now.Add(time.Hour)))
+	assert.NoError(t, err, "expired rule is extended")
 }
@@ -0,0 +1,47 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=clusterrules,shortName=trcr,scope=Cluster
+// +kubebuilder:printcolumn:name="direct",type="string",JSONPath=".spec.direct"
+// +kubebuilder:printcolumn:name="src-mac",type="string",JSONPath=".spec.match.srcMac"
+// +kubebuilder:printcolumn:name="dst-mac",type="string",JSONPath=".spec.match.dstMac"
+// +kubebuilder:printcolumn:name="mac",type="string",JSONPath=".spec.match.mac"
+// +kubebuilder:printcolumn:name="priority",type="integer",JSONPath=".spec.priority"
+// +kubebuilder:printcolumn:name="mode",type="string",JSONPath=".spec.action.mode"
+// +kubebuilder:printcolumn:name="suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="active",type="string",JSONPath=".status.conditions[?(@.type==\"Active\")].status"
+// +kubebuilder:printcolumn:name="accepted",type="string",JSONPath=".status.conditions[?(@.type==\"Accepted\")].status"
+// +kubebuilder:printcolumn:name="programmed",type="string",JSONPath=".status.conditions[?(@.type==\"Programmed\")].status"
+// +kubebuilder:printcolumn:name="conflicted",type="string",JSONPath=".status.conditions[?(@.type==\"Conflicted\")].status"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterRule is the cluster-scoped Rule for the global redirection policy of
+// platform admins. The label selector of match.selector selects workloads in
+// all namespaces.
+type ClusterRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired behavior for this ClusterRule.
+	Spec RuleSpec `json:"spec"`
+	// Most recently observed status of this ClusterRule.
+	Status RuleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ClusterRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRule `json:"items"`
+}
@@ -0,0 +1,14 @@
+package v1beta1
+
+// v1beta1 is the storage version and the hub of conversion, other versions
+// implement conversion.Convertible to convert from and to it.
+
+func (*Rule) Hub() {}
+
+func (*ClusterRule) Hub() {}
+
+func (*RedirectTarget) Hub() {}
+
+func (*RedirectExemption) Hub() {}
+
+func (*RuleQuota) Hub() {}
@@ -0,0 +1,5 @@
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen=package
+// +groupName=tr.everoute.io
+
+package v1beta1
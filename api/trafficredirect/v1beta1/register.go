@@ -0,0 +1,45 @@
+// Package v1beta1 contains API Schema definitions for the v1beta1 API group
+// +kubebuilder:object:generate=true
+// +groupName=tr.everoute.io
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+func init() {
+	SchemeBuilder.Register(
+		&Rule{},
+		&RuleList{},
+		&ClusterRule{},
+		&ClusterRuleList{},
+		&RedirectTarget{},
+		&RedirectTargetList{},
+		&RedirectExemption{},
+		&RedirectExemptionList{},
+		&RuleQuota{},
+		&RuleQuotaList{},
+	)
+}
+
+var (
+	// SchemeGroupVersion is group version used to register these objects
+	SchemeGroupVersion = schema.GroupVersion{Group: "tr.everoute.io", Version: "v1beta1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: SchemeGroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+// Kind takes an unqualified kind and returns a Group qualified GroupKind
+func Kind(kind string) schema.GroupKind {
+	return SchemeGroupVersion.WithKind(kind).GroupKind()
+}
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
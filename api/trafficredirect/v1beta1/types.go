@@ -0,0 +1,294 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=rules,shortName=trr
+// +kubebuilder:printcolumn:name="direct",type="string",JSONPath=".spec.direct"
+// +kubebuilder:printcolumn:name="src-mac",type="string",JSONPath=".spec.match.srcMac"
+// +kubebuilder:printcolumn:name="dst-mac",type="string",JSONPath=".spec.match.dstMac"
+// +kubebuilder:printcolumn:name="mac",type="string",JSONPath=".spec.match.mac"
+// +kubebuilder:printcolumn:name="priority",type="integer",JSONPath=".spec.priority"
+// +kubebuilder:printcolumn:name="mode",type="string",JSONPath=".spec.action.mode"
+// +kubebuilder:printcolumn:name="vlan",type="integer",JSONPath=".spec.match.vlanID"
+// +kubebuilder:printcolumn:name="ethertype",type="string",JSONPath=".spec.match.etherType"
+// +kubebuilder:printcolumn:name="vm",type="string",JSONPath=".spec.option.towerVM"
+// +kubebuilder:printcolumn:name="suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="active",type="string",JSONPath=".status.conditions[?(@.type==\"Active\")].status"
+// +kubebuilder:printcolumn:name="accepted",type="string",JSONPath=".status.conditions[?(@.type==\"Accepted\")].status"
+// +kubebuilder:printcolumn:name="programmed",type="string",JSONPath=".status.conditions[?(@.type==\"Programmed\")].status"
+// +kubebuilder:printcolumn:name="conflicted",type="string",JSONPath=".status.conditions[?(@.type==\"Conflicted\")].status"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+
+type Rule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired behavior for this Rule.
+	Spec RuleSpec `json:"spec"`
+	// Most recently observed status of this Rule.
+	Status RuleStatus `json:"status,omitempty"`
+}
+
+type RuleSpec struct {
+	Match RuleMatch `json:"match"`
+	// Direct both matches the egress and ingress traffic of match.mac.
+	// +kubebuilder:validation:Enum=ingress;egress;both
+	Direct RuleDirect `json:"direct"`
+	// Priority decides the winner when matches of rules overlap in the same
+	// direct, the higher value wins. Defaults to 0.
+	Priority int32 `json:"priority,omitempty"`
+	// Suspend stops agents from programming the rule without deleting it,
+	// the rule keeps suspended until it's unset. Defaults to false.
+	Suspend bool `json:"suspend,omitempty"`
+	// Schedule limits the rule to be active only in the time windows, the
+	// rule is always active when unset.
+	Schedule *RuleSchedule `json:"schedule,omitempty"`
+	// ExpiresAt is the time the rule is deleted at, it must be in the future
+	// when set or changed.
+	// +kubebuilder:validation:Format=date-time
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// TTL is the lifetime of the rule after its creation, e.g. 2h. The rule
+	// is deleted when the ttl elapsed.
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+	// Where and how the matched traffic is sent, agents send it to their
+	// default target in redirect mode when unset.
+	Action *RuleAction `json:"action,omitempty"`
+	// FailurePolicy is the behavior when the target is unavailable, open
+	// bypasses the inspection and closed drops the traffic. It applies to the
+	// default target of agents too. Defaults to open.
+	// +kubebuilder:validation:Enum=open;closed
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+	// SampleRate sends one of every N matched packets to the target in mirror
+	// mode, every packet is sent when unset.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	SampleRate *int32 `json:"sampleRate,omitempty"`
+	// TruncateBytes is the snap length of the packets sent to the target in
+	// mirror mode, packets are sent entirely when unset.
+	// +kubebuilder:validation:Minimum=64
+	// +kubebuilder:validation:Maximum=65535
+	TruncateBytes *int32 `json:"truncateBytes,omitempty"`
+	// tower info for debug
+	Option *Option `json:"option,omitempty"`
+}
+
+type RuleMatch struct {
+	// Source mac, or mac/mask to match a range of macs, e.g.
+	// 00:50:56:00:00:00/ff:ff:ff:00:00:00 matches the OUI 00:50:56.
+	SrcMac string `json:"srcMac,omitempty"`
+	// Destination mac, or mac/mask to match a range of macs.
+	DstMac string `json:"dstMac,omitempty"`
+	// Source macs or mac/masks, any of them is matched. Exclusive with srcMac.
+	SrcMacs []string `json:"srcMacs,omitempty"`
+	// Destination macs or mac/masks, any of them is matched. Exclusive with dstMac.
+	DstMacs []string `json:"dstMacs,omitempty"`
+	// MAC of the workload NIC for direct both, it's the srcMac of egress
+	// traffic and the dstMac of ingress traffic. Only for direct both.
+	Mac string `json:"mac,omitempty"`
+
+	// Source IPv4 or IPv6 CIDR, a single ip means host address.
+	SrcCIDR string `json:"srcCIDR,omitempty"`
+	// Destination IPv4 or IPv6 CIDR, a single ip means host address.
+	DstCIDR string `json:"dstCIDR,omitempty"`
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP;ICMP;ICMPv6
+	Protocol Protocol `json:"protocol,omitempty"`
+	// Source port range, only for protocol TCP, UDP and SCTP.
+	SrcPort *PortRange `json:"srcPort,omitempty"`
+	// Destination port range, only for protocol TCP, UDP and SCTP.
+	DstPort *PortRange `json:"dstPort,omitempty"`
+
+	// VLAN id to match, it's the begin of the range when vlanIDEnd is set.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	VlanID *int32 `json:"vlanID,omitempty"`
+	// End of the vlan range, inclusive.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	VlanIDEnd *int32 `json:"vlanIDEnd,omitempty"`
+	// EtherType name: ipv4, ipv6, arp, rarp, lldp, or a hex value like 0x88cc.
+	EtherType EtherType `json:"etherType,omitempty"`
+
+	// Selector selects the workloads whose NIC macs are matched instead of a
+	// literal mac, it takes the place of srcMac for egress, dstMac for ingress
+	// and mac for both. The selected macs are expanded into status.selectedMacs.
+	Selector *VMSelector `json:"selector,omitempty"`
+}
+
+// VMSelector selects workloads by any of the fields, the selected macs are the union.
+type VMSelector struct {
+	// IDs of Tower VMs.
+	TowerVMs []string `json:"towerVMs,omitempty"`
+	// Tower labels, VMs with any of the labels are selected.
+	TowerLabels []TowerLabel `json:"towerLabels,omitempty"`
+	// Label selector of pods and kubevirt VMIs in the namespace of the rule.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+type TowerLabel struct {
+	Key string `json:"key"`
+	// Value of the label, labels of any value are selected when unset.
+	Value string `json:"value,omitempty"`
+}
+
+// RuleSchedule is the time windows the rule is active in.
+type RuleSchedule struct {
+	// IANA name of the time zone of the windows, e.g. Asia/Shanghai. Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// The rule is active when the time is in any of the windows.
+	// +kubebuilder:validation:MinItems=1
+	Windows []ScheduleWindow `json:"windows"`
+}
+
+// ScheduleWindow is a daily time window, a window ends on the next day when
+// end is not after start.
+type ScheduleWindow struct {
+	// Days of week the window starts on, every day when unset.
+	// +kubebuilder:validation:items:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+	Days []string `json:"days,omitempty"`
+	// Start time of the day in form of HH:MM.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End time of the day in form of HH:MM, exclusive.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+type PortRange struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Begin int32 `json:"begin"`
+	// End of the range, inclusive. Equals to begin when unset.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	End int32 `json:"end,omitempty"`
+}
+
+type RuleAction struct {
+	// Mode redirect sends the traffic to the target inline, mirror sends a copy
+	// to the target and forwards the original, drop-copy sends a copy to the
+	// target and drops the original. Defaults to redirect.
+	// +kubebuilder:validation:Enum=redirect;mirror;drop-copy
+	Mode   ActionMode        `json:"mode,omitempty"`
+	Target RedirectTargetRef `json:"target"`
+}
+
+// RedirectTargetRef refers to the target of the traffic, exactly one field must be set.
+type RedirectTargetRef struct {
+	// MAC of the appliance NIC, e.g. the DPI appliance.
+	Mac string `json:"mac,omitempty"`
+	// Name of the service port on the node.
+	Port string `json:"port,omitempty"`
+	// Name of the RedirectTarget in the same namespace, the endpoint is
+	// selected by consistent hashing of the rule mac.
+	RedirectTarget string `json:"redirectTarget,omitempty"`
+}
+
+type Option struct {
+	TowerVM string `json:"towerVM,omitempty"`
+}
+
+type RuleStatus struct {
+	// The generation of the spec the status was computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions of the rule, known types are Accepted, Programmed, Conflicted,
+	// TargetResolved, Selected and Active.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Programming state reported by data-plane agents, one entry per node.
+	// +listType=map
+	// +listMapKey=node
+	Nodes []RuleNodeStatus `json:"nodes,omitempty"`
+	// Number of nodes which have programmed the observed generation.
+	ProgrammedNodes int32 `json:"programmedNodes,omitempty"`
+	// Number of nodes which failed to program the observed generation.
+	FailedNodes int32 `json:"failedNodes,omitempty"`
+	// Endpoint selected from the RedirectTarget of the action.
+	Target *ResolvedTarget `json:"target,omitempty"`
+	// Macs selected by match.selector.
+	// +listType=map
+	// +listMapKey=mac
+	SelectedMacs []SelectedMac `json:"selectedMacs,omitempty"`
+}
+
+type SelectedMac struct {
+	Mac string `json:"mac"`
+	// Workload the mac belongs to, e.g. tower-vm/<id>, pod/<name> or vmi/<name>.
+	Source string `json:"source,omitempty"`
+}
+
+type ResolvedTarget struct {
+	// Name of the RedirectTarget.
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Mac      string `json:"mac"`
+}
+
+type RuleNodeStatus struct {
+	Node string `json:"node"`
+	// The generation of the spec the agent has handled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	Programmed         bool  `json:"programmed"`
+	// Error message when the agent failed to program the rule.
+	Message        string      `json:"message,omitempty"`
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type RuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Rule `json:"items"`
+}
+
+type RuleDirect string
+
+const (
+	Egress  RuleDirect = "egress"
+	Ingress RuleDirect = "ingress"
+	Both    RuleDirect = "both"
+)
+
+type ActionMode string
+
+const (
+	ActionRedirect ActionMode = "redirect"
+	ActionMirror   ActionMode = "mirror"
+	ActionDropCopy ActionMode = "drop-copy"
+)
+
+type FailurePolicy string
+
+const (
+	FailOpen   FailurePolicy = "open"
+	FailClosed FailurePolicy = "closed"
+)
+
+type EtherType string
+
+const (
+	EtherTypeIPv4 EtherType = "ipv4"
+	EtherTypeIPv6 EtherType = "ipv6"
+	EtherTypeARP  EtherType = "arp"
+	EtherTypeRARP EtherType = "rarp"
+	EtherTypeLLDP EtherType = "lldp"
+)
+
+type Protocol string
+
+const (
+	ProtocolTCP    Protocol = "TCP"
+	ProtocolUDP    Protocol = "UDP"
+	ProtocolSCTP   Protocol = "SCTP"
+	ProtocolICMP   Protocol = "ICMP"
+	ProtocolICMPv6 Protocol = "ICMPv6"
+)
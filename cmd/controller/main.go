@@ -3,17 +3,28 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	// time zones of rule schedules are loaded without zoneinfo in the image
+	_ "time/tzdata"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
 	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1beta1"
 	"github.com/everoute/trafficredirect/pkg/config"
 	"github.com/everoute/trafficredirect/pkg/constants"
+	"github.com/everoute/trafficredirect/pkg/controller/cert"
+	"github.com/everoute/trafficredirect/pkg/controller/rule"
+	"github.com/everoute/trafficredirect/pkg/controller/schedule"
+	"github.com/everoute/trafficredirect/pkg/controller/selector"
+	"github.com/everoute/trafficredirect/pkg/controller/target"
 	"github.com/everoute/trafficredirect/pkg/controller/vnic"
 	"github.com/everoute/trafficredirect/pkg/tower/client"
 )
@@ -21,18 +32,40 @@ import (
 var Scheme = runtime.NewScheme()
 
 func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(Scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(Scheme))
 	utilruntime.Must(v1alpha1.AddToScheme(Scheme))
+	utilruntime.Must(v1beta1.AddToScheme(Scheme))
 }
 
 func main() {
 	klog.InitFlags(nil)
 	config.InitFlags(nil)
 	flag.Parse()
+	if p := v1alpha1.FailurePolicy(config.Config.VnicFailurePolicy); p != v1alpha1.FailOpen && p != v1alpha1.FailClosed {
+		klog.Fatalf("vnic-failure-policy must be open or closed, got %q", p)
+	}
+	// a wrong guess of the user blocks the controller from its own rules
+	if config.Config.ControllerUsername == "" {
+		klog.Warningf("controller-username is not set, rules managed by the controller are not protected")
+	}
 
 	ctrl.SetLogger(klog.Background())
 	stopCtx := ctrl.SetupSignalHandler()
 
 	cfg := ctrl.GetConfigOrDie()
+	tlsOpts := []func(*tls.Config){
+		func(conf *tls.Config) { conf.MinVersion = tls.VersionTLS13 },
+	}
+	var certCtrl *cert.Controller
+	if config.Config.WebhookCert.SelfSigned {
+		// the webhook server serves the cert in memory instead of watching WebhookCertPath
+		certCtrl = cert.NewController(cfg, Scheme)
+		if err := certCtrl.Sync(stopCtx); err != nil {
+			klog.Fatalf("Failed to sync webhook certs: %s", err)
+		}
+		tlsOpts = append(tlsOpts, func(conf *tls.Config) { conf.GetCertificate = certCtrl.GetCertificate })
+	}
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:                  Scheme,
 		MetricsBindAddress:      config.Config.MetricsAddr,
@@ -44,9 +77,7 @@ func main() {
 			Host:    config.Config.WebhookHost,
 			Port:    config.Config.WebhookPort,
 			CertDir: constants.WebhookCertPath,
-			TLSOpts: []func(*tls.Config){
-				func(conf *tls.Config) { conf.MinVersion = tls.VersionTLS13 },
-			},
+			TLSOpts: tlsOpts,
 		}),
 	})
 	if err != nil {
@@ -55,11 +86,64 @@ func main() {
 	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
 		klog.Fatalf("Failed to add healthz ping checker")
 	}
-	if err := (&v1alpha1.Rule{}).SetupWebhookWithManager(mgr); err != nil {
+	if certCtrl != nil {
+		if err := mgr.Add(certCtrl); err != nil {
+			klog.Fatalf("Failed to add webhook cert ctrl to mgr: %s", err)
+		}
+	}
+	// converts between v1alpha1 and the storage version v1beta1 for all kinds
+	mgr.GetWebhookServer().Register("/convert", conversion.NewWebhookHandler(mgr.GetScheme()))
+	// rules of the vnic controller cover every dpi enabled vnic, they are not duplicates of user rules
+	ruleValidator, err := v1alpha1.NewRuleValidator(mgr, config.Config.ControllerUsername, config.Config.WebhookRejectDuplicates, constants.VnicRuleNamespace)
+	if err != nil {
+		klog.Fatalf("unable to new rule validator: %s", err)
+	}
+	towerCli := client.NewClient()
+	switch config.Config.WebhookTowerVMCheck {
+	case "off":
+	case "warn", "strict":
+		ruleValidator.TowerVMs = client.NewVMCache(towerCli, config.Config.WebhookTowerVMCacheTTL)
+		ruleValidator.TowerVMStrict = config.Config.WebhookTowerVMCheck == "strict"
+	default:
+		klog.Fatalf("webhook-tower-vm-check must be off, warn or strict, got %q", config.Config.WebhookTowerVMCheck)
+	}
+	ruleValidator.Quota = &v1alpha1.RuleQuotaLimits{
+		NamespaceMax:     int32(config.Config.RuleQuota.NamespaceMax),
+		ClusterMax:       int32(config.Config.RuleQuota.ClusterMax),
+		ManagedNamespace: constants.VnicRuleNamespace,
+		ManagedMax:       int32(config.Config.RuleQuota.ManagedMax),
+		WarnPercent:      int32(config.Config.RuleQuota.WarnPercent),
+	}
+	if err := (&v1alpha1.Rule{}).SetupWebhookWithManager(mgr, ruleValidator); err != nil {
 		klog.Fatalf("unable to registry webhook for rule: %s", err)
 	}
+	if err := (&v1alpha1.ClusterRule{}).SetupWebhookWithManager(mgr, ruleValidator); err != nil {
+		klog.Fatalf("unable to registry webhook for cluster rule: %s", err)
+	}
+	if err := (&v1alpha1.RedirectExemption{}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Fatalf("unable to registry webhook for redirect exemption: %s", err)
+	}
+
+	ruleCtrl := rule.NewController(mgr)
+	if err := mgr.Add(ruleCtrl); err != nil {
+		klog.Fatalf("Failed to add rule ctrl to mgr: %s", err)
+	}
+
+	scheduleCtrl := schedule.NewController(mgr)
+	if err := mgr.Add(scheduleCtrl); err != nil {
+		klog.Fatalf("Failed to add rule schedule ctrl to mgr: %s", err)
+	}
+
+	targetCtrl := target.NewController(mgr, target.TCPProber{})
+	if err := mgr.Add(targetCtrl); err != nil {
+		klog.Fatalf("Failed to add redirect target ctrl to mgr: %s", err)
+	}
+
+	selectorCtrl := selector.NewController(mgr, towerCli)
+	if err := mgr.Add(selectorCtrl); err != nil {
+		klog.Fatalf("Failed to add rule selector ctrl to mgr: %s", err)
+	}
 
-	towerCli := client.NewClient()
 	vnicCtrl := vnic.NewController(mgr, towerCli)
 	if err := mgr.Add(vnicCtrl); err != nil {
 		klog.Fatalf("Failed to add vnic ctrl to mgr: %s", err)
@@ -13,14 +13,53 @@ type T struct {
 	HealthAddr  string
 	WebhookHost string
 	WebhookPort int
+	// reject the rules duplicating existing rules instead of warning
+	WebhookRejectDuplicates bool
+	// check option.towerVM of rules in tower: off, warn or strict
+	WebhookTowerVMCheck    string
+	WebhookTowerVMCacheTTL time.Duration
+	// the user of the controller, only it changes the rules managed by the controller
+	ControllerUsername string
 
 	EnableLeaderElection    bool
 	LeaderElectionNamespace string
 	LeaderElectionName      string
 
+	// create one rule of direct both per vnic instead of the ingress and egress pair
+	VnicBidirectionalRule bool
+	// failure policy of vnic rules, the tower vm label overrides it
+	VnicFailurePolicy string
+	// interval of the full resync of vnic rules with tower, only resync on start when it's zero
+	VnicResyncInterval time.Duration
+
+	WebhookCert WebhookCertOpts
+
+	RuleQuota RuleQuotaOpts
+
 	Tower TowerOpts
 }
 
+type WebhookCertOpts struct {
+	// generate the self-signed certs instead of reading them from WebhookCertPath
+	SelfSigned      bool
+	SecretNamespace string
+	SecretName      string
+	// comma separated dns names and ips of the serving cert
+	Hosts        string
+	Validity     time.Duration
+	RotateBefore time.Duration
+}
+
+// limits of the number of rules, zero means no limit
+type RuleQuotaOpts struct {
+	// default limit of the namespaces without RuleQuota
+	NamespaceMax int
+	ClusterMax   int
+	// limit of the rules managed by the controller, they don't take the cluster quota
+	ManagedMax  int
+	WarnPercent int
+}
+
 type TowerOpts struct {
 	AllowInsecure      bool
 	Addr               string
@@ -44,10 +83,31 @@ func InitFlags(flagset *flag.FlagSet) {
 	flagset.StringVar(&Config.HealthAddr, "health-addr", ":9601", "the health address")
 	flagset.StringVar(&Config.WebhookHost, "webhook-host", "127.0.0.1", "the webhook host")
 	flagset.IntVar(&Config.WebhookPort, "webhook-port", 9603, "the webhook port")
+	flagset.BoolVar(&Config.WebhookRejectDuplicates, "webhook-reject-duplicate-rules", false, "reject the rule overlapping an existing rule of the same mac, direct and priority, warn it when false")
+	flagset.StringVar(&Config.WebhookTowerVMCheck, "webhook-tower-vm-check", "off", "check the vm of rule option.towerVM exists in tower and owns the rule mac, off, warn or strict which rejects the rule")
+	flagset.DurationVar(&Config.WebhookTowerVMCacheTTL, "webhook-tower-vm-cache-ttl", 30*time.Second, "the ttl of tower vms cached by the webhook")
+	flagset.StringVar(&Config.ControllerUsername, "controller-username", "", "the user of the controller service account, e.g. system:serviceaccount:<namespace>:<name>, others can't change the rules managed by the controller without the break glass annotation, the rules are not protected when it's empty")
 	flagset.BoolVar(&Config.EnableLeaderElection, "enable-leader-election", true, "enable leader election or not")
 	flagset.StringVar(&Config.LeaderElectionNamespace, "leader-election-namespace", "kube-system", "the namespace of leader election lease")
 	flagset.StringVar(&Config.LeaderElectionName, "leader-election-name", "tr-controller.leader-election.everoute.io", "the name of leader election lease")
 
+	flagset.BoolVar(&Config.VnicBidirectionalRule, "vnic-bidirectional-rule", false, "create one rule of direct both per vnic, the legacy ingress and egress rules are cleaned up")
+
+	flagset.DurationVar(&Config.VnicResyncInterval, "vnic-resync-interval", 30*time.Minute, "the interval of the full resync of vnic rules with the dpi enabled vnics in tower, the resync on start is retried until it succeeds, only resync on start when it's 0")
+	flagset.StringVar(&Config.VnicFailurePolicy, "vnic-failure-policy", "open", "failure policy of vnic rules, open or closed, the tower vm label tr-failure-policy overrides it")
+
+	flagset.BoolVar(&Config.WebhookCert.SelfSigned, "webhook-self-signed-cert", false, "generate the self-signed webhook certs, store them in the secret and inject the ca into the webhook configurations and crd conversion webhooks")
+	flagset.StringVar(&Config.WebhookCert.SecretNamespace, "webhook-cert-secret-namespace", "kube-system", "the namespace of the secret of the self-signed webhook certs")
+	flagset.StringVar(&Config.WebhookCert.SecretName, "webhook-cert-secret-name", "everoute-tr-webhook-cert", "the name of the secret of the self-signed webhook certs")
+	flagset.StringVar(&Config.WebhookCert.Hosts, "webhook-cert-hosts", "127.0.0.1", "comma separated dns names and ips of the self-signed webhook serving cert")
+	flagset.DurationVar(&Config.WebhookCert.Validity, "webhook-cert-validity", 365*24*time.Hour, "the validity of the self-signed webhook certs")
+	flagset.DurationVar(&Config.WebhookCert.RotateBefore, "webhook-cert-rotate-before", 30*24*time.Hour, "rotate the self-signed webhook certs the duration before they expire")
+
+	flagset.IntVar(&Config.RuleQuota.NamespaceMax, "rule-quota-namespace-max", 0, "the max number of rules of the namespaces without RuleQuota, 0 means no limit")
+	flagset.IntVar(&Config.RuleQuota.ClusterMax, "rule-quota-cluster-max", 0, "the max number of rules and cluster rules except the rules managed by the controller, 0 means no limit")
+	flagset.IntVar(&Config.RuleQuota.ManagedMax, "rule-quota-managed-max", 0, "the max number of rules managed by the controller in namespace tr-tower, 0 means no limit")
+	flagset.IntVar(&Config.RuleQuota.WarnPercent, "rule-quota-warn-percent", 80, "warn the rule creation when the usage of a rule quota reaches the percent, 0 means no warning")
+
 	flagset.BoolVar(&Config.Tower.AllowInsecure, "tower-allow-insecure", true, "tower allow-insecure for authenticate")
 	flagset.StringVar(&Config.Tower.Addr, "tower-addr", "", "tower api address host:port")
 	flagset.StringVar(&Config.Tower.Scheme, "tower-scheme", "https", "tower api scheme")
@@ -5,4 +5,10 @@ const (
 
 	VnicRuleNamespace = "tr-tower"
 	VnicRulePrefix    = "vnic"
+	// VnicRuleManager is the value of label tr.everoute.io/managed-by of vnic rules.
+	VnicRuleManager = "vnic-controller"
+
+	// TowerFailurePolicyLabel is the key of the tower vm label which sets the
+	// failure policy of the vnic rules, the value is open or closed.
+	TowerFailurePolicyLabel = "tr-failure-policy"
 )
@@ -0,0 +1,172 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+const (
+	// keys of the secret, tls.crt and tls.key are the keys of kubernetes.io/tls
+	caCertKey  = "ca.crt"
+	caKeyKey   = "ca.key"
+	tlsCertKey = "tls.crt"
+	tlsKeyKey  = "tls.key"
+)
+
+// certs is the self-signed ca and the serving cert signed by it, caBundle is
+// the ca followed by the previous ca not expired, so the serving certs of both
+// are trusted during the rotation.
+type certs struct {
+	caBundle []byte
+	caKey    []byte
+	tlsCert  []byte
+	tlsKey   []byte
+}
+
+func (c *certs) data() map[string][]byte {
+	return map[string][]byte{
+		caCertKey:  c.caBundle,
+		caKeyKey:   c.caKey,
+		tlsCertKey: c.tlsCert,
+		tlsKeyKey:  c.tlsKey,
+	}
+}
+
+func certsFromData(data map[string][]byte) *certs {
+	return &certs{
+		caBundle: data[caCertKey],
+		caKey:    data[caKeyKey],
+		tlsCert:  data[tlsCertKey],
+		tlsKey:   data[tlsKeyKey],
+	}
+}
+
+// generateCerts generates a new ca and the serving cert of hosts valid for
+// validity from now, the first ca of prevBundle is kept in the bundle until it
+// expires.
+func generateCerts(hosts []string, validity time.Duration, now time.Time, prevBundle []byte) (*certs, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          newSerial(now),
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("everoute-tr-webhook-ca@%d", now.Unix())},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: newSerial(now),
+		Subject:      pkix.Name{CommonName: "everoute-tr-webhook"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	caKeyPEM, err := encodeKey(caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	if prev, err := parseCert(prevBundle); err == nil && now.Before(prev.NotAfter) {
+		bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: prev.Raw})...)
+	}
+	return &certs{
+		caBundle: bundle,
+		caKey:    caKeyPEM,
+		tlsCert:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		tlsKey:   keyPEM,
+	}, nil
+}
+
+// verify checks the serving cert is signed by the ca and valid for hosts
+// longer than rotateBefore, it returns the error telling why the certs are
+// renewed.
+func (c *certs) verify(hosts []string, now time.Time, rotateBefore time.Duration) error {
+	if len(c.caBundle) == 0 || len(c.caKey) == 0 || len(c.tlsCert) == 0 || len(c.tlsKey) == 0 {
+		return fmt.Errorf("certs are incomplete")
+	}
+	cert, err := parseCert(c.tlsCert)
+	if err != nil {
+		return err
+	}
+	if !now.Add(rotateBefore).Before(cert.NotAfter) {
+		return fmt.Errorf("cert expires at %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(c.caBundle) {
+		return fmt.Errorf("ca bundle is invalid")
+	}
+	for _, h := range hosts {
+		if err := cert.VerifyHostname(h); err != nil {
+			return err
+		}
+	}
+	_, err = cert.Verify(x509.VerifyOptions{Roots: roots, CurrentTime: now})
+	return err
+}
+
+// parseCert parses the first cert of the pem data.
+func parseCert(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+func newSerial(now time.Time) *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return big.NewInt(now.UnixNano())
+	}
+	return serial
+}
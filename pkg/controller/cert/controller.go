@@ -0,0 +1,260 @@
+package cert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect"
+	"github.com/everoute/trafficredirect/pkg/config"
+)
+
+const (
+	ValidatingWebhookName = "everoute-tr-validate"
+	MutatingWebhookName   = "everoute-tr-mutate"
+
+	// every replica checks the secret periodically, it rotates the certs
+	// before expiry or reloads the certs rotated by other replicas
+	resyncInterval = 10 * time.Minute
+)
+
+// syncBackoff retries the failed sync up to resyncInterval apart.
+var syncBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Jitter: 0.1, Steps: 10, Cap: resyncInterval}
+
+// Controller manages the self-signed certs of the webhook server. The certs
+// are stored in a secret shared by the replicas, the ca is injected into the
+// caBundle of the webhook configurations and the conversion webhook of the
+// CRDs, and the serving cert is reloaded by the webhook server without restart.
+type Controller struct {
+	k8scli       k8sclient.Client
+	secret       types.NamespacedName
+	hosts        []string
+	validity     time.Duration
+	rotateBefore time.Duration
+	clock        clock.PassiveClock
+
+	cert atomic.Pointer[tls.Certificate]
+}
+
+// NewController returns the cert controller reading the secret directly, it
+// works before the cache of the manager starts.
+func NewController(cfg *rest.Config, scheme *runtime.Scheme) *Controller {
+	k8scli, err := k8sclient.New(cfg, k8sclient.Options{Scheme: scheme})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to new client of webhook cert controller")
+		os.Exit(1)
+	}
+	return &Controller{
+		k8scli:       k8scli,
+		secret:       types.NamespacedName{Namespace: config.Config.WebhookCert.SecretNamespace, Name: config.Config.WebhookCert.SecretName},
+		hosts:        strings.Split(config.Config.WebhookCert.Hosts, ","),
+		validity:     config.Config.WebhookCert.Validity,
+		rotateBefore: config.Config.WebhookCert.RotateBefore,
+		clock:        clock.RealClock{},
+	}
+}
+
+// Start resyncs the certs until the ctx is done, it runs on every replica as
+// every replica serves the webhook. A failed sync is retried with backoff.
+func (c *Controller) Start(ctx context.Context) error {
+	backoff := syncBackoff
+	timer := time.NewTimer(resyncInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+			if err := c.Sync(ctx); err != nil {
+				ctrl.Log.Error(err, "Failed to sync webhook certs")
+				timer.Reset(backoff.Step())
+				continue
+			}
+			backoff = syncBackoff
+			timer.Reset(resyncInterval)
+		}
+	}
+}
+
+func (c *Controller) NeedLeaderElection() bool {
+	return false
+}
+
+// GetCertificate returns the serving cert for the tls config of the webhook server.
+func (c *Controller) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert := c.cert.Load()
+	if cert == nil {
+		return nil, fmt.Errorf("webhook serving cert is not loaded")
+	}
+	return cert, nil
+}
+
+// Sync renews the certs in the secret when they're missing or about to
+// expire, injects the ca into the webhooks and loads the serving cert. The
+// serving cert is switched after the ca is injected, so it's always trusted
+// by the api server.
+func (c *Controller) Sync(ctx context.Context) error {
+	log := ctrl.Log.WithName("webhook-cert").WithValues("secret", c.secret)
+	certs, err := c.syncSecret(ctx)
+	if err != nil {
+		log.Error(err, "Failed to sync secret of webhook certs")
+		return err
+	}
+
+	cert, err := tls.X509KeyPair(certs.tlsCert, certs.tlsKey)
+	if err != nil {
+		log.Error(err, "Failed to load webhook serving cert")
+		return err
+	}
+	if err := c.injectCABundle(ctx, certs.caBundle); err != nil {
+		log.Error(err, "Failed to inject ca bundle into webhooks")
+		return err
+	}
+
+	if old := c.cert.Load(); old == nil || !bytes.Equal(old.Certificate[0], cert.Certificate[0]) {
+		c.cert.Store(&cert)
+		log.Info("Success to load webhook serving cert")
+	}
+	return nil
+}
+
+func (c *Controller) syncSecret(ctx context.Context) (*certs, error) {
+	log := ctrl.Log.WithName("webhook-cert").WithValues("secret", c.secret)
+	now := c.clock.Now()
+	secret := &corev1.Secret{}
+	err := c.k8scli.Get(ctx, c.secret, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+
+	current := certsFromData(secret.Data)
+	reason := current.verify(c.hosts, now, c.rotateBefore)
+	if reason == nil {
+		return current, nil
+	}
+	renewed, err := generateCerts(c.hosts, c.validity, now, current.caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	// replicas race on the secret by the resource version, the loser loads
+	// the certs of the winner
+	if !exists {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: c.secret.Namespace, Name: c.secret.Name},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       renewed.data(),
+		}
+		err = c.k8scli.Create(ctx, secret)
+	} else {
+		secret.Data = renewed.data()
+		err = c.k8scli.Update(ctx, secret)
+	}
+	if errors.IsAlreadyExists(err) || errors.IsConflict(err) {
+		log.Info("Webhook certs are renewed by another replica, reload them")
+		return c.reloadSecret(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Success to renew webhook certs", "reason", reason.Error())
+	return renewed, nil
+}
+
+// reloadSecret reads the certs renewed by another replica.
+func (c *Controller) reloadSecret(ctx context.Context) (*certs, error) {
+	secret := &corev1.Secret{}
+	if err := c.k8scli.Get(ctx, c.secret, secret); err != nil {
+		return nil, err
+	}
+	// the certs are renewed on the next sync if they don't fit this replica
+	current := certsFromData(secret.Data)
+	if _, err := parseCert(current.tlsCert); err != nil {
+		return nil, fmt.Errorf("certs renewed by another replica are invalid: %s", err)
+	}
+	return current, nil
+}
+
+// injectCABundle sets the caBundle of every webhook of the webhook configurations
+// and the conversion webhook of the CRDs of the group.
+func (c *Controller) injectCABundle(ctx context.Context, caBundle []byte) error {
+	validating := &admissionv1.ValidatingWebhookConfiguration{}
+	if err := c.k8scli.Get(ctx, types.NamespacedName{Name: ValidatingWebhookName}, validating); k8sclient.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil {
+		changed := false
+		for i := range validating.Webhooks {
+			if !bytes.Equal(validating.Webhooks[i].ClientConfig.CABundle, caBundle) {
+				validating.Webhooks[i].ClientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := c.k8scli.Update(ctx, validating); err != nil {
+				return err
+			}
+			ctrl.Log.Info("Success to inject ca bundle", "webhook", ValidatingWebhookName)
+		}
+	}
+
+	mutating := &admissionv1.MutatingWebhookConfiguration{}
+	if err := c.k8scli.Get(ctx, types.NamespacedName{Name: MutatingWebhookName}, mutating); k8sclient.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil {
+		changed := false
+		for i := range mutating.Webhooks {
+			if !bytes.Equal(mutating.Webhooks[i].ClientConfig.CABundle, caBundle) {
+				mutating.Webhooks[i].ClientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if changed {
+			if err := c.k8scli.Update(ctx, mutating); err != nil {
+				return err
+			}
+			ctrl.Log.Info("Success to inject ca bundle", "webhook", MutatingWebhookName)
+		}
+	}
+
+	// the storage version differs from the version clients use, every
+	// request of the resources goes through the conversion webhook
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := c.k8scli.List(ctx, crds); err != nil {
+		return err
+	}
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		conv := crd.Spec.Conversion
+		if crd.Spec.Group != trafficredirect.GroupName || conv == nil || conv.Webhook == nil || conv.Webhook.ClientConfig == nil {
+			continue
+		}
+		if bytes.Equal(conv.Webhook.ClientConfig.CABundle, caBundle) {
+			continue
+		}
+		conv.Webhook.ClientConfig.CABundle = caBundle
+		if err := c.k8scli.Update(ctx, crd); err != nil {
+			return err
+		}
+		ctrl.Log.Info("Success to inject ca bundle", "crd", crd.Name)
+	}
+	return nil
+}
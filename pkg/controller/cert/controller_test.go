@@ -0,0 +1,246 @@
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	testingclock "k8s.io/utils/clock/testing"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestCert(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cert Suite")
+}
+
+var _ = Describe("Webhook cert controller", func() {
+	var (
+		ctx    context.Context
+		k8scli k8sclient.Client
+		now    = time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC)
+		key    = types.NamespacedName{Namespace: "kube-system", Name: "everoute-tr-webhook-cert"}
+	)
+
+	newController := func(at time.Time) *Controller {
+		return &Controller{
+			k8scli:       k8scli,
+			secret:       key,
+			hosts:        []string{"127.0.0.1", "tr-webhook.kube-system.svc"},
+			validity:     365 * 24 * time.Hour,
+			rotateBefore: 30 * 24 * time.Hour,
+			clock:        testingclock.NewFakePassiveClock(at),
+		}
+	}
+
+	servingCert := func(c *Controller) *x509.Certificate {
+		cert, err := c.GetCertificate(nil)
+		Expect(err).NotTo(HaveOccurred())
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		Expect(err).NotTo(HaveOccurred())
+		return parsed
+	}
+
+	caBundles := func() [][]byte {
+		validating := &admissionv1.ValidatingWebhookConfiguration{}
+		Expect(k8scli.Get(ctx, types.NamespacedName{Name: ValidatingWebhookName}, validating)).To(Succeed())
+		mutating := &admissionv1.MutatingWebhookConfiguration{}
+		Expect(k8scli.Get(ctx, types.NamespacedName{Name: MutatingWebhookName}, mutating)).To(Succeed())
+		var bundles [][]byte
+		for _, w := range validating.Webhooks {
+			bundles = append(bundles, w.ClientConfig.CABundle)
+		}
+		for _, w := range mutating.Webhooks {
+			bundles = append(bundles, w.ClientConfig.CABundle)
+		}
+		return bundles
+	}
+
+	verify := func(cert *x509.Certificate, bundle []byte, at time.Time) error {
+		roots := x509.NewCertPool()
+		Expect(roots.AppendCertsFromPEM(bundle)).To(BeTrue())
+		_, err := cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: "127.0.0.1", CurrentTime: at})
+		return err
+	}
+
+	countCerts := func(bundle []byte) int {
+		n := 0
+		for block, rest := pem.Decode(bundle); block != nil; block, rest = pem.Decode(rest) {
+			n++
+		}
+		return n
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		conversionCRD := func(name, group string) *apiextensionsv1.CustomResourceDefinition {
+			return &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Group: group,
+					Conversion: &apiextensionsv1.CustomResourceConversion{
+						Strategy: apiextensionsv1.WebhookConverter,
+						Webhook: &apiextensionsv1.WebhookConversion{
+							ClientConfig: &apiextensionsv1.WebhookClientConfig{CABundle: []byte("\n")},
+						},
+					},
+				},
+			}
+		}
+		k8scli = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&admissionv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: ValidatingWebhookName},
+				Webhooks:   []admissionv1.ValidatingWebhook{{Name: "rule.tr.io"}, {Name: "clusterrule.tr.io"}},
+			},
+			&admissionv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: MutatingWebhookName},
+				Webhooks:   []admissionv1.MutatingWebhook{{Name: "rule.tr.io"}, {Name: "clusterrule.tr.io"}},
+			},
+			conversionCRD("rules.tr.everoute.io", "tr.everoute.io"),
+			conversionCRD("redirecttargets.tr.everoute.io", "tr.everoute.io"),
+			conversionCRD("others.example.io", "example.io"),
+		).Build()
+	})
+
+	It("should generate certs and inject ca bundle", func() {
+		c := newController(now)
+		_, err := c.GetCertificate(nil)
+		Expect(err).To(HaveOccurred())
+
+		Expect(c.Sync(ctx)).To(Succeed())
+		secret := &corev1.Secret{}
+		Expect(k8scli.Get(ctx, key, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKey(caCertKey))
+
+		bundles := caBundles()
+		Expect(bundles).To(HaveLen(4))
+		for _, b := range bundles {
+			Expect(b).To(Equal(secret.Data[caCertKey]))
+		}
+		cert := servingCert(c)
+		Expect(verify(cert, bundles[0], now)).To(Succeed())
+		Expect(cert.DNSNames).To(ConsistOf("tr-webhook.kube-system.svc"))
+
+		By("keep the valid certs")
+		Expect(c.Sync(ctx)).To(Succeed())
+		kept := &corev1.Secret{}
+		Expect(k8scli.Get(ctx, key, kept)).To(Succeed())
+		Expect(kept.ResourceVersion).To(Equal(secret.ResourceVersion))
+	})
+
+	It("should inject ca bundle into conversion webhooks of the crds", func() {
+		c := newController(now)
+		Expect(c.Sync(ctx)).To(Succeed())
+		secret := &corev1.Secret{}
+		Expect(k8scli.Get(ctx, key, secret)).To(Succeed())
+
+		caBundle := func(name string) []byte {
+			crd := &apiextensionsv1.CustomResourceDefinition{}
+			Expect(k8scli.Get(ctx, types.NamespacedName{Name: name}, crd)).To(Succeed())
+			return crd.Spec.Conversion.Webhook.ClientConfig.CABundle
+		}
+		Expect(caBundle("rules.tr.everoute.io")).To(Equal(secret.Data[caCertKey]))
+		Expect(caBundle("redirecttargets.tr.everoute.io")).To(Equal(secret.Data[caCertKey]))
+		Expect(caBundle("others.example.io")).To(Equal([]byte("\n")), "crds of other groups are not changed")
+		Expect(verify(servingCert(c), caBundle("rules.tr.everoute.io"), now)).To(Succeed())
+	})
+
+	It("should rotate certs before expiry and trust both cas", func() {
+		c := newController(now)
+		Expect(c.Sync(ctx)).To(Succeed())
+		oldCert := servingCert(c)
+
+		rotateAt := now.Add(336 * 24 * time.Hour)
+		c.clock = testingclock.NewFakePassiveClock(rotateAt)
+		Expect(c.Sync(ctx)).To(Succeed())
+		newCert := servingCert(c)
+		Expect(newCert.SerialNumber).NotTo(Equal(oldCert.SerialNumber))
+
+		bundle := caBundles()[0]
+		Expect(countCerts(bundle)).To(Equal(2))
+		Expect(verify(newCert, bundle, rotateAt)).To(Succeed())
+		Expect(verify(oldCert, bundle, rotateAt)).To(Succeed(), "replicas not reloaded yet are trusted")
+
+		By("other replicas reload the rotated certs")
+		other := newController(rotateAt)
+		Expect(other.Sync(ctx)).To(Succeed())
+		Expect(servingCert(other).SerialNumber).To(Equal(newCert.SerialNumber))
+	})
+
+	It("should keep the old cert when failed to inject ca bundle", func() {
+		c := newController(now)
+		Expect(c.Sync(ctx)).To(Succeed())
+		oldCert := servingCert(c)
+		oldBundle := caBundles()[0]
+
+		rotateAt := now.Add(336 * 24 * time.Hour)
+		c.clock = testingclock.NewFakePassiveClock(rotateAt)
+		c.k8scli = interceptor.NewClient(k8scli.(k8sclient.WithWatch), interceptor.Funcs{
+			Update: func(ctx context.Context, cli k8sclient.WithWatch, obj k8sclient.Object, opts ...k8sclient.UpdateOption) error {
+				if _, ok := obj.(*admissionv1.ValidatingWebhookConfiguration); ok {
+					return fmt.Errorf("apiserver unavailable")
+				}
+				return cli.Update(ctx, obj, opts...)
+			},
+		})
+		Expect(c.Sync(ctx)).To(MatchError(ContainSubstring("apiserver unavailable")))
+		Expect(servingCert(c).SerialNumber).To(Equal(oldCert.SerialNumber))
+		Expect(caBundles()[0]).To(Equal(oldBundle))
+
+		By("switch the cert after the ca bundle is injected")
+		c.k8scli = k8scli
+		Expect(c.Sync(ctx)).To(Succeed())
+		Expect(servingCert(c).SerialNumber).NotTo(Equal(oldCert.SerialNumber))
+		Expect(verify(servingCert(c), caBundles()[0], rotateAt)).To(Succeed())
+	})
+
+	It("should load the certs of the replica winning the race", func() {
+		winner := newController(now)
+		Expect(winner.Sync(ctx)).To(Succeed())
+
+		loser := newController(now)
+		missed := false
+		loser.k8scli = interceptor.NewClient(k8scli.(k8sclient.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, cli k8sclient.WithWatch, key k8sclient.ObjectKey, obj k8sclient.Object, opts ...k8sclient.GetOption) error {
+				if _, ok := obj.(*corev1.Secret); ok && !missed {
+					// the secret is created by the winner after the loser reads it
+					missed = true
+					return errors.NewNotFound(corev1.Resource("secrets"), key.Name)
+				}
+				return cli.Get(ctx, key, obj, opts...)
+			},
+		})
+		Expect(loser.Sync(ctx)).To(Succeed())
+		Expect(missed).To(BeTrue())
+		Expect(servingCert(loser).SerialNumber).To(Equal(servingCert(winner).SerialNumber))
+	})
+
+	It("should renew certs missing the hosts", func() {
+		c := newController(now)
+		Expect(c.Sync(ctx)).To(Succeed())
+		oldCert := servingCert(c)
+
+		c.hosts = append(c.hosts, "10.0.0.1")
+		Expect(c.Sync(ctx)).To(Succeed())
+		Expect(servingCert(c).SerialNumber).NotTo(Equal(oldCert.SerialNumber))
+		Expect(servingCert(c).IPAddresses).To(HaveLen(2))
+	})
+})
@@ -0,0 +1,345 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+	"github.com/everoute/trafficredirect/pkg/source"
+)
+
+// Controller maintains the status of rules: it validates the spec, detects
+// rules overridden by overlapping rules and summarizes the programming state
+// reported by data-plane agents.
+type Controller struct {
+	k8scli k8sclient.Client
+	ruleW  controller.Controller
+}
+
+func NewController(mgr ctrl.Manager) *Controller {
+	c := &Controller{
+		k8scli: mgr.GetClient(),
+	}
+
+	for _, obj := range []k8sclient.Object{&v1alpha1.Rule{}, &v1alpha1.ClusterRule{}} {
+		err := mgr.GetFieldIndexer().IndexField(context.Background(), obj, v1alpha1.RuleOverlapIndex, v1alpha1.RuleOverlapIndexFunc)
+		if err != nil {
+			ctrl.Log.Error(err, "Failed to index rule overlap", "kind", fmt.Sprintf("%T", obj))
+			os.Exit(1)
+		}
+	}
+
+	var err error
+
+	c.ruleW, err = controller.NewUnmanaged("rule-status", mgr, controller.Options{Reconciler: reconcile.Func(c.handle)})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to new rule status controller")
+		os.Exit(1)
+	}
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.Rule{}), c.ruleHandler())
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch rule")
+		os.Exit(1)
+	}
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.ClusterRule{}), c.ruleHandler())
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch cluster rule")
+		os.Exit(1)
+	}
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.RedirectTarget{}), handler.EnqueueRequestsFromMapFunc(c.targetToRules))
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch redirect target")
+		os.Exit(1)
+	}
+
+	return c
+}
+
+func (c *Controller) Start(ctx context.Context) error {
+	return c.ruleW.Start(ctx)
+}
+
+// handle reconciles both Rule and ClusterRule, requests of ClusterRule have
+// no namespace as a Rule must have one.
+func (c *Controller) handle(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(4).Info("Reconciling rule status start")
+	defer log.V(4).Info("Reconciling rule status end")
+
+	var obj v1alpha1.RuleObject = &v1alpha1.Rule{}
+	if req.Namespace == "" {
+		obj = &v1alpha1.ClusterRule{}
+	}
+	if err := c.k8scli.Get(ctx, req.NamespacedName, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get rule")
+		return ctrl.Result{}, err
+	}
+
+	status := obj.GetRuleStatus().DeepCopy()
+	computeStatus(obj, status)
+	if err := c.computeConflict(ctx, obj, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := c.resolveTarget(ctx, obj, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	if equality.Semantic.DeepEqual(obj.GetRuleStatus(), status) {
+		return ctrl.Result{}, nil
+	}
+	*obj.GetRuleStatus() = *status
+	if err := c.k8scli.Status().Update(ctx, obj); err != nil {
+		log.Error(err, "Failed to update rule status")
+		return ctrl.Result{}, err
+	}
+	log.V(2).Info("Success to update rule status", "status", status)
+	return ctrl.Result{}, nil
+}
+
+func computeStatus(rule v1alpha1.RuleObject, status *v1alpha1.RuleStatus) {
+	generation := rule.GetGeneration()
+	status.ObservedGeneration = generation
+
+	accepted := metav1.Condition{
+		Type:               v1alpha1.RuleConditionAccepted,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             v1alpha1.ReasonValid,
+		Message:            "rule spec is valid",
+	}
+	if err := rule.Validate(); err != nil {
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = v1alpha1.ReasonInvalid
+		accepted.Message = err.Error()
+	}
+	meta.SetStatusCondition(&status.Conditions, accepted)
+
+	status.ProgrammedNodes, status.FailedNodes = 0, 0
+	for _, n := range status.Nodes {
+		if n.ObservedGeneration != generation {
+			continue
+		}
+		if n.Programmed {
+			status.ProgrammedNodes++
+		} else {
+			status.FailedNodes++
+		}
+	}
+
+	programmed := metav1.Condition{
+		Type:               v1alpha1.RuleConditionProgrammed,
+		ObservedGeneration: generation,
+	}
+	switch {
+	case status.FailedNodes > 0:
+		programmed.Status = metav1.ConditionFalse
+		programmed.Reason = v1alpha1.ReasonProgrammingFailed
+		programmed.Message = fmt.Sprintf("%d of %d nodes failed to program the rule", status.FailedNodes, status.FailedNodes+status.ProgrammedNodes)
+	case status.ProgrammedNodes > 0:
+		programmed.Status = metav1.ConditionTrue
+		programmed.Reason = v1alpha1.ReasonProgrammed
+		programmed.Message = fmt.Sprintf("rule programmed on %d nodes", status.ProgrammedNodes)
+	default:
+		programmed.Status = metav1.ConditionUnknown
+		programmed.Reason = v1alpha1.ReasonPending
+		programmed.Message = "no node has reported the current generation"
+	}
+	meta.SetStatusCondition(&status.Conditions, programmed)
+}
+
+// computeConflict sets the Conflicted condition, the rule is conflicted when
+// an overlapping valid Rule or ClusterRule precedes it.
+func (c *Controller) computeConflict(ctx context.Context, obj v1alpha1.RuleObject, status *v1alpha1.RuleStatus) error {
+	if obj.Validate() != nil {
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.RuleConditionConflicted)
+		return nil
+	}
+
+	rule := obj.AsRule()
+	peers, err := c.overlapCandidates(ctx, rule)
+	if err != nil {
+		return err
+	}
+	var winners []string
+	for _, peer := range peers {
+		p := peer.AsRule()
+		if peer.Validate() != nil || !p.Overlaps(rule) || !p.Precedes(rule) {
+			continue
+		}
+		winners = append(winners, v1alpha1.RuleRef(peer))
+	}
+	sort.Strings(winners)
+
+	conflicted := metav1.Condition{
+		Type:               v1alpha1.RuleConditionConflicted,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             v1alpha1.ReasonNoConflict,
+		Message:            "no overlapping rule takes precedence",
+	}
+	if len(winners) != 0 {
+		conflicted.Status = metav1.ConditionTrue
+		conflicted.Reason = v1alpha1.ReasonOverridden
+		conflicted.Message = fmt.Sprintf("overridden by overlapping rules %s", strings.Join(winners, ", "))
+	}
+	meta.SetStatusCondition(&status.Conditions, conflicted)
+	return nil
+}
+
+// overlapCandidates returns the other Rules and ClusterRules which may overlap the rule.
+func (c *Controller) overlapCandidates(ctx context.Context, rule *v1alpha1.Rule) ([]v1alpha1.RuleObject, error) {
+	var rules []v1alpha1.RuleObject
+	seen := make(map[types.NamespacedName]bool)
+	seen[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}] = true
+	add := func(obj v1alpha1.RuleObject) {
+		key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		if !seen[key] {
+			seen[key] = true
+			rules = append(rules, obj)
+		}
+	}
+
+	for _, key := range v1alpha1.RuleOverlapCandidateKeys(rule) {
+		opt := k8sclient.MatchingFields{v1alpha1.RuleOverlapIndex: key}
+		list := &v1alpha1.RuleList{}
+		if err := c.k8scli.List(ctx, list, opt); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "Failed to list overlapping rules", "key", key)
+			return nil, err
+		}
+		for i := range list.Items {
+			add(&list.Items[i])
+		}
+		clusterList := &v1alpha1.ClusterRuleList{}
+		if err := c.k8scli.List(ctx, clusterList, opt); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "Failed to list overlapping cluster rules", "key", key)
+			return nil, err
+		}
+		for i := range clusterList.Items {
+			add(&clusterList.Items[i])
+		}
+	}
+	return rules, nil
+}
+
+// ruleHandler enqueues the rule, and the overlapping rules when the spec or
+// the selected macs changed, as their Conflicted condition may change too.
+func (c *Controller) ruleHandler() handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.RateLimitingInterface, obj k8sclient.Object, peers bool) {
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}})
+		ruleObj, ok := obj.(v1alpha1.RuleObject)
+		if !ok || !peers {
+			return
+		}
+		rule := ruleObj.AsRule()
+		candidates, err := c.overlapCandidates(ctx, rule)
+		if err != nil {
+			return
+		}
+		for _, candidate := range candidates {
+			if candidate.AsRule().Overlaps(rule) {
+				q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: candidate.GetNamespace(), Name: candidate.GetName()}})
+			}
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, q, e.Object, true)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			changed := e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+			// macs selected by the selector decide the overlapping too
+			oldRule, okOld := e.ObjectOld.(v1alpha1.RuleObject)
+			newRule, okNew := e.ObjectNew.(v1alpha1.RuleObject)
+			if okOld && okNew && !equality.Semantic.DeepEqual(oldRule.GetRuleStatus().SelectedMacs, newRule.GetRuleStatus().SelectedMacs) {
+				changed = true
+			}
+			if changed {
+				enqueue(ctx, q, e.ObjectOld, true)
+			}
+			enqueue(ctx, q, e.ObjectNew, changed)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, q, e.Object, true)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			enqueue(ctx, q, e.Object, false)
+		},
+	}
+}
+
+// resolveTarget selects the endpoint of the RedirectTarget referred by the rule action.
+func (c *Controller) resolveTarget(ctx context.Context, obj v1alpha1.RuleObject, status *v1alpha1.RuleStatus) error {
+	rule := obj.AsRule()
+	// cluster rules can't refer to the namespaced RedirectTarget
+	if rule.Spec.Action == nil || rule.Spec.Action.Target.RedirectTarget == "" || rule.Namespace == "" {
+		status.Target = nil
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.RuleConditionTargetResolved)
+		return nil
+	}
+
+	resolved := metav1.Condition{
+		Type:               v1alpha1.RuleConditionTargetResolved,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: rule.Generation,
+	}
+	key := types.NamespacedName{Namespace: rule.Namespace, Name: rule.Spec.Action.Target.RedirectTarget}
+	target := &v1alpha1.RedirectTarget{}
+	err := c.k8scli.Get(ctx, key, target)
+	switch {
+	case errors.IsNotFound(err):
+		status.Target = nil
+		resolved.Reason = v1alpha1.ReasonTargetNotFound
+		resolved.Message = fmt.Sprintf("redirect target %s not found", key.Name)
+	case err != nil:
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to get redirect target", "target", key)
+		return err
+	default:
+		ep := target.Status.Pick(rule.HashKey())
+		if ep == nil {
+			status.Target = nil
+			resolved.Reason = v1alpha1.ReasonNoAvailableEndpoint
+			resolved.Message = fmt.Sprintf("redirect target %s has no available endpoint", key.Name)
+			break
+		}
+		status.Target = &v1alpha1.ResolvedTarget{Name: key.Name, Endpoint: ep.Name, Mac: ep.Mac}
+		resolved.Status = metav1.ConditionTrue
+		resolved.Reason = v1alpha1.ReasonResolved
+		resolved.Message = fmt.Sprintf("endpoint %s of redirect target %s is selected", ep.Name, key.Name)
+	}
+	meta.SetStatusCondition(&status.Conditions, resolved)
+	return nil
+}
+
+func (c *Controller) targetToRules(ctx context.Context, obj k8sclient.Object) []reconcile.Request {
+	rules := &v1alpha1.RuleList{}
+	if err := c.k8scli.List(ctx, rules, k8sclient.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list rules of redirect target", "target", obj.GetName())
+		return nil
+	}
+	var reqs []reconcile.Request
+	for i := range rules.Items {
+		r := &rules.Items[i]
+		if r.Spec.Action != nil && r.Spec.Action.Target.RedirectTarget == obj.GetName() {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: r.Namespace, Name: r.Name}})
+		}
+	}
+	return reqs
+}
@@ -0,0 +1,254 @@
+package rule
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+)
+
+func TestRule(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rule Suite")
+}
+
+func newTestController(objs ...k8sclient.Object) *Controller {
+	scheme := runtime.NewScheme()
+	Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+	builder := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.Rule{}, &v1alpha1.ClusterRule{}, &v1alpha1.RedirectTarget{}).WithObjects(objs...).
+		WithIndex(&v1alpha1.Rule{}, v1alpha1.RuleOverlapIndex, v1alpha1.RuleOverlapIndexFunc).
+		WithIndex(&v1alpha1.ClusterRule{}, v1alpha1.RuleOverlapIndex, v1alpha1.RuleOverlapIndexFunc)
+	return &Controller{k8scli: builder.Build()}
+}
+
+func newTestRule(name string, generation int64) *v1alpha1.Rule {
+	return &v1alpha1.Rule{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: generation},
+		Spec: v1alpha1.RuleSpec{
+			Direct: v1alpha1.Egress,
+			Match:  v1alpha1.RuleMatch{SrcMac: "00:11:22:33:44:55"},
+		},
+	}
+}
+
+var _ = Describe("Rule status controller", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	reconcileAndGet := func(c *Controller, name string) *v1alpha1.Rule {
+		key := types.NamespacedName{Namespace: "default", Name: name}
+		_, err := c.handle(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		rule := &v1alpha1.Rule{}
+		Expect(c.k8scli.Get(ctx, key, rule)).To(Succeed())
+		return rule
+	}
+
+	It("should ignore rule not found", func() {
+		c := newTestController()
+		_, err := c.handle(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "none"}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should set accepted and pending programmed for a new rule", func() {
+		c := newTestController(newTestRule("r1", 1))
+		rule := reconcileAndGet(c, "r1")
+
+		Expect(rule.Status.ObservedGeneration).To(Equal(int64(1)))
+		Expect(meta.IsStatusConditionTrue(rule.Status.Conditions, v1alpha1.RuleConditionAccepted)).To(BeTrue())
+		programmed := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionProgrammed)
+		Expect(programmed).NotTo(BeNil())
+		Expect(programmed.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(programmed.Reason).To(Equal(v1alpha1.ReasonPending))
+	})
+
+	It("should set accepted false for an invalid rule", func() {
+		r := newTestRule("r1", 1)
+		r.Spec.Match.SrcMac = "invalid"
+		c := newTestController(r)
+		rule := reconcileAndGet(c, "r1")
+
+		accepted := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionAccepted)
+		Expect(accepted.Status).To(Equal(metav1.ConditionFalse))
+		Expect(accepted.Reason).To(Equal(v1alpha1.ReasonInvalid))
+		Expect(accepted.Message).To(ContainSubstring("mac invalid is invalid"))
+	})
+
+	It("should summarize node status of the current generation", func() {
+		r := newTestRule("r1", 2)
+		r.Status.SetNodeStatus(v1alpha1.RuleNodeStatus{Node: "node1", ObservedGeneration: 2, Programmed: true})
+		r.Status.SetNodeStatus(v1alpha1.RuleNodeStatus{Node: "node2", ObservedGeneration: 2, Programmed: true})
+		r.Status.SetNodeStatus(v1alpha1.RuleNodeStatus{Node: "node3", ObservedGeneration: 1, Programmed: false})
+		c := newTestController(r)
+		rule := reconcileAndGet(c, "r1")
+
+		Expect(rule.Status.ProgrammedNodes).To(Equal(int32(2)))
+		Expect(rule.Status.FailedNodes).To(Equal(int32(0)))
+		Expect(meta.IsStatusConditionTrue(rule.Status.Conditions, v1alpha1.RuleConditionProgrammed)).To(BeTrue())
+	})
+
+	It("should set programmed false when any node failed", func() {
+		r := newTestRule("r1", 1)
+		r.Status.SetNodeStatus(v1alpha1.RuleNodeStatus{Node: "node1", ObservedGeneration: 1, Programmed: true})
+		r.Status.SetNodeStatus(v1alpha1.RuleNodeStatus{Node: "node2", ObservedGeneration: 1, Message: "no such port"})
+		c := newTestController(r)
+		rule := reconcileAndGet(c, "r1")
+
+		programmed := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionProgrammed)
+		Expect(programmed.Status).To(Equal(metav1.ConditionFalse))
+		Expect(programmed.Reason).To(Equal(v1alpha1.ReasonProgrammingFailed))
+		Expect(programmed.Message).To(ContainSubstring("1 of 2 nodes"))
+	})
+
+	It("should not update status when nothing changed", func() {
+		c := newTestController(newTestRule("r1", 1))
+		rule := reconcileAndGet(c, "r1")
+		rule2 := reconcileAndGet(c, "r1")
+		Expect(rule2.ResourceVersion).To(Equal(rule.ResourceVersion))
+	})
+
+	Context("conflict", func() {
+		newPriorityRule := func(name string, priority int32) *v1alpha1.Rule {
+			r := newTestRule(name, 1)
+			r.UID = types.UID(name)
+			r.Spec.Priority = priority
+			return r
+		}
+
+		It("should set conflicted on the rule with lower priority", func() {
+			c := newTestController(newPriorityRule("high", 10), newPriorityRule("low", 0))
+			high := reconcileAndGet(c, "high")
+			low := reconcileAndGet(c, "low")
+
+			Expect(meta.IsStatusConditionFalse(high.Status.Conditions, v1alpha1.RuleConditionConflicted)).To(BeTrue())
+			cond := meta.FindStatusCondition(low.Status.Conditions, v1alpha1.RuleConditionConflicted)
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(v1alpha1.ReasonOverridden))
+			Expect(cond.Message).To(ContainSubstring("default/high"))
+		})
+
+		It("should break ties by name", func() {
+			c := newTestController(newPriorityRule("r1", 0), newPriorityRule("r2", 0))
+			Expect(meta.IsStatusConditionFalse(reconcileAndGet(c, "r1").Status.Conditions, v1alpha1.RuleConditionConflicted)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(reconcileAndGet(c, "r2").Status.Conditions, v1alpha1.RuleConditionConflicted)).To(BeTrue())
+		})
+
+		It("should not conflict with rules not overlapping", func() {
+			other := newPriorityRule("other", 10)
+			other.Spec.Match.SrcMac = "00:11:22:33:44:66"
+			ingress := newPriorityRule("ingress", 10)
+			ingress.Spec.Direct = v1alpha1.Ingress
+			invalid := newPriorityRule("invalid", 10)
+			invalid.Spec.Match.DstCIDR = "invalid"
+			c := newTestController(newPriorityRule("r1", 0), other, ingress, invalid)
+			rule := reconcileAndGet(c, "r1")
+			Expect(meta.IsStatusConditionFalse(rule.Status.Conditions, v1alpha1.RuleConditionConflicted)).To(BeTrue())
+		})
+
+		It("should not set conflicted on an invalid rule", func() {
+			r := newPriorityRule("r1", 0)
+			r.Spec.Match.SrcMac = "invalid"
+			c := newTestController(r, newPriorityRule("high", 10))
+			rule := reconcileAndGet(c, "r1")
+			Expect(meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionConflicted)).To(BeNil())
+		})
+
+		It("should detect conflict between rule and cluster rule", func() {
+			cr := &v1alpha1.ClusterRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "platform", UID: "platform", Generation: 1},
+				Spec:       newPriorityRule("platform", 10).Spec,
+			}
+			c := newTestController(cr, newPriorityRule("low", 0))
+
+			key := types.NamespacedName{Name: "platform"}
+			_, err := c.handle(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.k8scli.Get(ctx, key, cr)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(cr.Status.Conditions, v1alpha1.RuleConditionAccepted)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(cr.Status.Conditions, v1alpha1.RuleConditionConflicted)).To(BeTrue())
+
+			cond := meta.FindStatusCondition(reconcileAndGet(c, "low").Status.Conditions, v1alpha1.RuleConditionConflicted)
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Message).To(ContainSubstring("clusterrule/platform"))
+		})
+	})
+
+	Context("redirect target", func() {
+		newTargetRule := func(name, mac string) *v1alpha1.Rule {
+			r := newTestRule(name, 1)
+			r.Spec.Match.SrcMac = mac
+			r.Spec.Action = &v1alpha1.RuleAction{
+				Mode:   v1alpha1.ActionRedirect,
+				Target: v1alpha1.RedirectTargetRef{RedirectTarget: "dpi"},
+			}
+			return r
+		}
+		newTarget := func(eps ...string) *v1alpha1.RedirectTarget {
+			t := &v1alpha1.RedirectTarget{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dpi"}}
+			for _, ep := range eps {
+				e := v1alpha1.TargetEndpoint{Name: ep, Mac: "00:00:00:00:00:0" + ep[len(ep)-1:], Weight: 1}
+				t.Spec.Endpoints = append(t.Spec.Endpoints, e)
+				t.Status.AvailableEndpoints = append(t.Status.AvailableEndpoints, e)
+			}
+			return t
+		}
+
+		It("should report target not found", func() {
+			c := newTestController(newTargetRule("r1", "00:11:22:33:44:55"))
+			rule := reconcileAndGet(c, "r1")
+			cond := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionTargetResolved)
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(v1alpha1.ReasonTargetNotFound))
+			Expect(rule.Status.Target).To(BeNil())
+		})
+
+		It("should report target without available endpoint", func() {
+			c := newTestController(newTargetRule("r1", "00:11:22:33:44:55"), newTarget())
+			rule := reconcileAndGet(c, "r1")
+			cond := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionTargetResolved)
+			Expect(cond.Reason).To(Equal(v1alpha1.ReasonNoAvailableEndpoint))
+		})
+
+		It("should select the endpoint by rule mac", func() {
+			t := newTarget("ep1", "ep2", "ep3")
+			c := newTestController(newTargetRule("r1", "00:11:22:33:44:55"), t)
+			rule := reconcileAndGet(c, "r1")
+			Expect(meta.IsStatusConditionTrue(rule.Status.Conditions, v1alpha1.RuleConditionTargetResolved)).To(BeTrue())
+			want := t.Status.Pick("00:11:22:33:44:55")
+			Expect(rule.Status.Target).To(Equal(&v1alpha1.ResolvedTarget{Name: "dpi", Endpoint: want.Name, Mac: want.Mac}))
+		})
+
+		It("should clean resolved target when action changed", func() {
+			c := newTestController(newTargetRule("r1", "00:11:22:33:44:55"), newTarget("ep1"))
+			rule := reconcileAndGet(c, "r1")
+			Expect(rule.Status.Target).NotTo(BeNil())
+
+			rule.Spec.Action = nil
+			Expect(c.k8scli.Update(ctx, rule)).To(Succeed())
+			rule = reconcileAndGet(c, "r1")
+			Expect(rule.Status.Target).To(BeNil())
+			Expect(meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionTargetResolved)).To(BeNil())
+		})
+
+		It("should map target to rules referring it", func() {
+			other := newTestRule("r3", 1)
+			c := newTestController(newTargetRule("r1", "00:11:22:33:44:55"), newTargetRule("r2", "00:11:22:33:44:66"), other)
+			reqs := c.targetToRules(ctx, newTarget("ep1"))
+			Expect(reqs).To(HaveLen(2))
+		})
+	})
+})
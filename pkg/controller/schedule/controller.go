@@ -0,0 +1,155 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+	"github.com/everoute/trafficredirect/pkg/source"
+)
+
+// Controller sets the Active condition of rules by their suspend and
+// schedule, and deletes the rules expired.
+type Controller struct {
+	k8scli k8sclient.Client
+	ruleW  controller.Controller
+	clock  clock.PassiveClock
+}
+
+func NewController(mgr ctrl.Manager) *Controller {
+	c := &Controller{
+		k8scli: mgr.GetClient(),
+		clock:  clock.RealClock{},
+	}
+
+	var err error
+	c.ruleW, err = controller.NewUnmanaged("rule-schedule", mgr, controller.Options{Reconciler: reconcile.Func(c.handle)})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to new rule schedule controller")
+		os.Exit(1)
+	}
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.Rule{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch rule")
+		os.Exit(1)
+	}
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.ClusterRule{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch cluster rule")
+		os.Exit(1)
+	}
+
+	return c
+}
+
+func (c *Controller) Start(ctx context.Context) error {
+	return c.ruleW.Start(ctx)
+}
+
+// handle requeues the rule at the next change of the schedule or the expiration.
+func (c *Controller) handle(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(4).Info("Reconciling rule schedule start")
+	defer log.V(4).Info("Reconciling rule schedule end")
+
+	// requests of ClusterRule have no namespace
+	var obj v1alpha1.RuleObject = &v1alpha1.Rule{}
+	if req.Namespace == "" {
+		obj = &v1alpha1.ClusterRule{}
+	}
+	if err := c.k8scli.Get(ctx, req.NamespacedName, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get rule")
+		return ctrl.Result{}, err
+	}
+	if obj.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	now := c.clock.Now()
+	spec := obj.GetRuleSpec()
+	var requeue time.Duration
+	if expire := spec.ExpireTime(obj.GetCreationTimestamp()); expire != nil {
+		if !now.Before(*expire) {
+			if err := c.k8scli.Delete(ctx, obj); k8sclient.IgnoreNotFound(err) != nil {
+				log.Error(err, "Failed to delete expired rule")
+				return ctrl.Result{}, err
+			}
+			log.Info("Success to delete expired rule", "expireTime", expire)
+			return ctrl.Result{}, nil
+		}
+		requeue = expire.Sub(now)
+	}
+
+	status := obj.GetRuleStatus().DeepCopy()
+	next := computeActive(obj, status, now)
+	if !next.IsZero() && (requeue == 0 || next.Sub(now) < requeue) {
+		requeue = next.Sub(now)
+	}
+
+	if !equality.Semantic.DeepEqual(obj.GetRuleStatus(), status) {
+		*obj.GetRuleStatus() = *status
+		if err := c.k8scli.Status().Update(ctx, obj); err != nil {
+			log.Error(err, "Failed to update rule active condition")
+			return ctrl.Result{}, err
+		}
+		log.V(2).Info("Success to update rule active condition", "active", meta.IsStatusConditionTrue(status.Conditions, v1alpha1.RuleConditionActive))
+	}
+	return ctrl.Result{RequeueAfter: requeue}, nil
+}
+
+// computeActive sets the Active condition, and returns the next time the
+// condition may change, zero when it never changes. A suspended rule is
+// inactive regardless of its schedule.
+func computeActive(obj v1alpha1.RuleObject, status *v1alpha1.RuleStatus, now time.Time) time.Time {
+	active := metav1.Condition{
+		Type:               v1alpha1.RuleConditionActive,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             v1alpha1.ReasonNoSchedule,
+		Message:            "rule has no schedule",
+	}
+	var next time.Time
+	if obj.GetRuleSpec().Suspend {
+		active.Status = metav1.ConditionFalse
+		active.Reason = v1alpha1.ReasonSuspended
+		active.Message = "rule is suspended"
+	} else if schedule := obj.GetRuleSpec().Schedule; schedule != nil {
+		in, n, err := schedule.Active(now)
+		switch {
+		case err != nil:
+			active.Status = metav1.ConditionFalse
+			active.Reason = v1alpha1.ReasonInvalid
+			active.Message = err.Error()
+		case in:
+			active.Reason = v1alpha1.ReasonInSchedule
+			active.Message = fmt.Sprintf("in schedule windows, next transition at %s", n.Format(time.RFC3339))
+		default:
+			active.Status = metav1.ConditionFalse
+			active.Reason = v1alpha1.ReasonOutOfSchedule
+			active.Message = "out of schedule windows"
+			if !n.IsZero() {
+				active.Message = fmt.Sprintf("out of schedule windows, next transition at %s", n.Format(time.RFC3339))
+			}
+		}
+		next = n
+	}
+	meta.SetStatusCondition(&status.Conditions, active)
+	return next
+}
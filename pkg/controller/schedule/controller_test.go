@@ -0,0 +1,132 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	testingclock "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+)
+
+func TestSchedule(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Schedule Suite")
+}
+
+var _ = Describe("Rule schedule controller", func() {
+	var (
+		ctx context.Context
+		c   *Controller
+		// 2026-10-12 10:00 is Monday
+		now     = time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC)
+		created = metav1.NewTime(now.Add(-time.Hour))
+		key     = types.NamespacedName{Namespace: "default", Name: "r1"}
+	)
+
+	newRule := func() *v1alpha1.Rule {
+		return &v1alpha1.Rule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, Generation: 1, CreationTimestamp: created},
+			Spec: v1alpha1.RuleSpec{
+				Direct: v1alpha1.Egress,
+				Match:  v1alpha1.RuleMatch{SrcMac: "00:11:22:33:44:55"},
+			},
+		}
+	}
+
+	setup := func(objs ...k8sclient.Object) {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		c = &Controller{
+			k8scli: fake.NewClientBuilder().WithScheme(scheme).
+				WithStatusSubresource(&v1alpha1.Rule{}, &v1alpha1.ClusterRule{}).WithObjects(objs...).Build(),
+			clock: testingclock.NewFakePassiveClock(now),
+		}
+	}
+
+	reconcileAndGet := func() (*v1alpha1.Rule, ctrl.Result) {
+		res, err := c.handle(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		rule := &v1alpha1.Rule{}
+		Expect(c.k8scli.Get(ctx, key, rule)).To(Succeed())
+		return rule, res
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should set active for rule without schedule", func() {
+		setup(newRule())
+		rule, res := reconcileAndGet()
+		cond := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionActive)
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(v1alpha1.ReasonNoSchedule))
+		Expect(res.RequeueAfter).To(BeZero())
+	})
+
+	It("should flip active by schedule windows", func() {
+		r := newRule()
+		r.Spec.Schedule = &v1alpha1.RuleSchedule{Windows: []v1alpha1.ScheduleWindow{{Start: "09:00", End: "18:00"}}}
+		setup(r)
+		rule, res := reconcileAndGet()
+		Expect(meta.IsStatusConditionTrue(rule.Status.Conditions, v1alpha1.RuleConditionActive)).To(BeTrue())
+		Expect(res.RequeueAfter).To(Equal(8 * time.Hour))
+
+		c.clock = testingclock.NewFakePassiveClock(now.Add(8 * time.Hour))
+		rule, res = reconcileAndGet()
+		cond := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionActive)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(v1alpha1.ReasonOutOfSchedule))
+		Expect(res.RequeueAfter).To(Equal(15 * time.Hour))
+	})
+
+	It("should deactivate suspended rule regardless of schedule", func() {
+		r := newRule()
+		r.Spec.Suspend = true
+		r.Spec.Schedule = &v1alpha1.RuleSchedule{Windows: []v1alpha1.ScheduleWindow{{Start: "09:00", End: "18:00"}}}
+		setup(r)
+		rule, res := reconcileAndGet()
+		cond := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionActive)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(v1alpha1.ReasonSuspended))
+		Expect(res.RequeueAfter).To(BeZero())
+	})
+
+	It("should requeue at expiration and delete expired rule", func() {
+		r := newRule()
+		r.Spec.TTL = &metav1.Duration{Duration: 2 * time.Hour}
+		setup(r)
+		_, res := reconcileAndGet()
+		Expect(res.RequeueAfter).To(Equal(time.Hour))
+
+		c.clock = testingclock.NewFakePassiveClock(now.Add(time.Hour))
+		_, err := c.handle(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(c.k8scli.Get(ctx, key, &v1alpha1.Rule{}))).To(BeTrue())
+	})
+
+	It("should delete expired cluster rule", func() {
+		expired := metav1.NewTime(now.Add(-time.Minute))
+		cr := &v1alpha1.ClusterRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "cr1", CreationTimestamp: created},
+			Spec:       newRule().Spec,
+		}
+		cr.Spec.ExpiresAt = &expired
+		setup(cr)
+		_, err := c.handle(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "cr1"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errors.IsNotFound(c.k8scli.Get(ctx, types.NamespacedName{Name: "cr1"}, &v1alpha1.ClusterRule{}))).To(BeTrue())
+	})
+})
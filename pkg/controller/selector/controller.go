@@ -0,0 +1,448 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/everoute/graphc/pkg/crcwatch"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	ctrlsource "sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+	"github.com/everoute/trafficredirect/pkg/source"
+	"github.com/everoute/trafficredirect/pkg/tower/client"
+	"github.com/everoute/trafficredirect/pkg/tower/datamodel"
+)
+
+const (
+	CrcChanSize = 100
+
+	// towerVMSourcePrefix is the prefix of the source of macs selected from tower vms
+	towerVMSourcePrefix = "tower-vm/"
+)
+
+// Controller expands the selector of rules into the selected macs in status,
+// and re-expands them when the membership in Tower or kubernetes changes.
+type Controller struct {
+	towerCli *client.Client
+	k8scli   k8sclient.Client
+	ruleW    controller.Controller
+	crcW     *crcwatch.Watch
+	// tower events trigger the rules selecting the changed tower vms to re-expand
+	towerEvents chan event.GenericEvent
+	// a resync of all rules of tower selector is queued for the dropped tower events
+	resyncPending atomic.Bool
+	// kubevirt is installed, VMIs are selected by label selector
+	vmiEnabled bool
+}
+
+func NewController(mgr ctrl.Manager, towerCli *client.Client) *Controller {
+	c := &Controller{
+		towerCli:    towerCli,
+		k8scli:      mgr.GetClient(),
+		towerEvents: make(chan event.GenericEvent, CrcChanSize),
+	}
+
+	var err error
+	c.ruleW, err = controller.NewUnmanaged("rule-selector", mgr, controller.Options{Reconciler: reconcile.Func(c.handle)})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to new rule selector controller")
+		os.Exit(1)
+	}
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.Rule{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch rule")
+		os.Exit(1)
+	}
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.ClusterRule{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch cluster rule")
+		os.Exit(1)
+	}
+	// macs of pods are in the network status annotation, only the metadata of pods is cached
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), newPodMetadataObject()), handler.EnqueueRequestsFromMapFunc(c.workloadToRules),
+		workloadChangedPredicate(podMacs))
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch pod")
+		os.Exit(1)
+	}
+	if _, err := mgr.GetRESTMapper().RESTMapping(vmiGVK.GroupKind(), vmiGVK.Version); err == nil {
+		c.vmiEnabled = true
+		// macs of vmis are in status.interfaces
+		err = c.ruleW.Watch(source.Kind(mgr.GetCache(), newVMIObject()), handler.EnqueueRequestsFromMapFunc(c.workloadToRules),
+			workloadChangedPredicate(vmiMacs))
+		if err != nil {
+			ctrl.Log.Error(err, "Failed to watch vmi")
+			os.Exit(1)
+		}
+	} else {
+		ctrl.Log.Info("Kubevirt is not installed, label selector only selects pods", "err", err)
+	}
+	err = c.ruleW.Watch(&ctrlsource.Channel{Source: c.towerEvents}, handler.EnqueueRequestsFromMapFunc(c.towerToRules))
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch tower events")
+		os.Exit(1)
+	}
+
+	c.crcW, err = client.NewCRCWatch([]datamodel.ResourceType{datamodel.TypeVM, datamodel.TypeVMNic, datamodel.TypeLabel})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to new crc watch")
+		os.Exit(1)
+	}
+	c.crcW.RegistryHandler(c.crcHandler)
+
+	return c
+}
+
+func (c *Controller) Start(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return c.ruleW.Start(ctx)
+	})
+
+	g.Go(func() error {
+		c.crcW.Start(ctx.Done())
+		return nil
+	})
+
+	return g.Wait()
+}
+
+func (c *Controller) crcHandler(e *models.ResourceChangeEvent) {
+	log := ctrl.Log.WithName("selector-crcwatch")
+	if e == nil || e.ResourceType == nil || e.ResourceID == nil {
+		log.Info("Invalid crc event, skip")
+		return
+	}
+	log.V(4).Info("Received crc event", "type", *e.ResourceType, "id", *e.ResourceID)
+
+	// the event object carries the type and id of the tower resource
+	ev := event.GenericEvent{Object: &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{Kind: *e.ResourceType},
+		ObjectMeta: metav1.ObjectMeta{Name: *e.ResourceID},
+	}}
+	select {
+	case c.towerEvents <- ev:
+	default:
+		// never block the crc reader shared with other watches, the event without
+		// type re-expands all rules of tower selector in place of the dropped events
+		if c.resyncPending.CompareAndSwap(false, true) {
+			log.Info("Tower events channel is full, resync all rules of tower selector")
+			go func() { c.towerEvents <- event.GenericEvent{Object: &metav1.PartialObjectMetadata{}} }()
+		}
+	}
+}
+
+func (c *Controller) handle(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(4).Info("Reconciling rule selector start")
+	defer log.V(4).Info("Reconciling rule selector end")
+
+	// requests of ClusterRule have no namespace
+	var obj v1alpha1.RuleObject = &v1alpha1.Rule{}
+	if req.Namespace == "" {
+		obj = &v1alpha1.ClusterRule{}
+	}
+	if err := c.k8scli.Get(ctx, req.NamespacedName, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get rule")
+		return ctrl.Result{}, err
+	}
+
+	rule := obj.AsRule()
+	status := rule.Status.DeepCopy()
+	var selectErr error
+	if rule.Spec.Match.Selector == nil {
+		status.SelectedMacs = nil
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.RuleConditionSelected)
+	} else {
+		selected := metav1.Condition{
+			Type:               v1alpha1.RuleConditionSelected,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: rule.Generation,
+			Reason:             v1alpha1.ReasonSelected,
+		}
+		macs, err := c.selectMacs(ctx, rule)
+		if err != nil {
+			// keep the macs selected last time until tower is back
+			selectErr = err
+			selected.Status = metav1.ConditionFalse
+			selected.Reason = v1alpha1.ReasonSelectFailed
+			selected.Message = err.Error()
+		} else {
+			status.SelectedMacs = macs
+			selected.Message = fmt.Sprintf("%d macs selected", len(macs))
+		}
+		meta.SetStatusCondition(&status.Conditions, selected)
+	}
+
+	if !equality.Semantic.DeepEqual(obj.GetRuleStatus(), status) {
+		*obj.GetRuleStatus() = *status
+		if err := c.k8scli.Status().Update(ctx, obj); err != nil {
+			log.Error(err, "Failed to update rule selected macs")
+			return ctrl.Result{}, err
+		}
+		log.Info("Success to update rule selected macs", "count", len(status.SelectedMacs))
+	}
+	return ctrl.Result{}, selectErr
+}
+
+// selectMacs returns the macs of workloads selected by the rule, sorted by mac.
+// Label selector of a ClusterRule selects workloads in all namespaces.
+func (c *Controller) selectMacs(ctx context.Context, rule *v1alpha1.Rule) ([]v1alpha1.SelectedMac, error) {
+	sel := rule.Spec.Match.Selector
+	macs := make(map[string]string)
+
+	if len(sel.TowerVMs) != 0 || len(sel.TowerLabels) != 0 {
+		if err := c.selectTowerMacs(ctx, sel, macs); err != nil {
+			return nil, err
+		}
+	}
+	if sel.LabelSelector != nil {
+		if err := c.selectK8sMacs(ctx, rule.Namespace, sel.LabelSelector, macs); err != nil {
+			return nil, err
+		}
+	}
+
+	selected := make([]v1alpha1.SelectedMac, 0, len(macs))
+	for mac, source := range macs {
+		selected = append(selected, v1alpha1.SelectedMac{Mac: mac, Source: source})
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Mac < selected[j].Mac })
+	return selected, nil
+}
+
+func (c *Controller) selectTowerMacs(ctx context.Context, sel *v1alpha1.VMSelector, macs map[string]string) error {
+	log := ctrl.LoggerFrom(ctx)
+	for _, id := range sel.TowerVMs {
+		vm := &datamodel.VM{}
+		exists, err := c.towerCli.Get(ctx, id, vm)
+		if err != nil {
+			log.Error(err, "Failed to get vm from tower", "vm", id)
+			return fmt.Errorf("get tower vm %s: %s", id, err)
+		}
+		if exists {
+			addVMMacs(vm, macs)
+		}
+	}
+	for _, l := range sel.TowerLabels {
+		labels := datamodel.Labels{}
+		if err := c.towerCli.List(ctx, datamodel.LabelWhere(l.Key, l.Value), &labels); err != nil {
+			log.Error(err, "Failed to list labels from tower", "key", l.Key, "value", l.Value)
+			return fmt.Errorf("list tower label %s=%s: %s", l.Key, l.Value, err)
+		}
+		for i := range labels {
+			for j := range labels[i].VMs {
+				addVMMacs(&labels[i].VMs[j], macs)
+			}
+		}
+	}
+	return nil
+}
+
+func addVMMacs(vm *datamodel.VM, macs map[string]string) {
+	for _, nic := range vm.VMNics {
+		addMac(macs, nic.MacAddress, towerVMSourcePrefix+vm.ID)
+	}
+}
+
+func (c *Controller) selectK8sMacs(ctx context.Context, namespace string, ls *metav1.LabelSelector, macs map[string]string) error {
+	log := ctrl.LoggerFrom(ctx)
+	selector, err := metav1.LabelSelectorAsSelector(ls)
+	if err != nil {
+		return err
+	}
+	opts := []k8sclient.ListOption{k8sclient.InNamespace(namespace), k8sclient.MatchingLabelsSelector{Selector: selector}}
+
+	pods := newPodMetadataListObject()
+	if err := c.k8scli.List(ctx, pods, opts...); err != nil {
+		log.Error(err, "Failed to list pods")
+		return err
+	}
+	for i := range pods.Items {
+		for _, mac := range podMacs(&pods.Items[i]) {
+			addMac(macs, mac, "pod/"+pods.Items[i].Name)
+		}
+	}
+
+	if !c.vmiEnabled {
+		return nil
+	}
+	vmis := newVMIListObject()
+	if err := c.k8scli.List(ctx, vmis, opts...); err != nil {
+		log.Error(err, "Failed to list vmis")
+		return err
+	}
+	for i := range vmis.Items {
+		for _, mac := range vmiMacs(&vmis.Items[i]) {
+			addMac(macs, mac, "vmi/"+vmis.Items[i].GetName())
+		}
+	}
+	return nil
+}
+
+func (c *Controller) workloadToRules(ctx context.Context, obj k8sclient.Object) []reconcile.Request {
+	// old labels are unknown on update, all rules of label selector in the namespace
+	// and all cluster rules of label selector are synced
+	return c.rulesToRequests(ctx, func(r *v1alpha1.Rule) bool {
+		return (r.Namespace == "" || r.Namespace == obj.GetNamespace()) && r.Spec.Match.Selector.LabelSelector != nil
+	})
+}
+
+func (c *Controller) towerToRules(ctx context.Context, obj k8sclient.Object) []reconcile.Request {
+	kind := datamodel.ResourceType(obj.GetObjectKind().GroupVersionKind().Kind)
+	if kind == "" {
+		// the events dropped from now on need another resync
+		c.resyncPending.Store(false)
+	}
+	change := c.resolveTowerChange(ctx, kind, obj.GetName())
+	return c.rulesToRequests(ctx, func(r *v1alpha1.Rule) bool {
+		sel := r.Spec.Match.Selector
+		return (len(sel.TowerVMs) != 0 || len(sel.TowerLabels) != 0) && change.affects(r)
+	})
+}
+
+// towerChange is the tower vms and labels changed by a tower event.
+type towerChange struct {
+	vms    sets.Set[string]
+	labels []datamodel.Label
+	// the change can't be resolved, e.g. the vm of a deleted nic is unknown
+	all bool
+	// the label can't be resolved, e.g. it's deleted
+	allLabels bool
+}
+
+// resolveTowerChange queries tower for the vms and labels changed by the
+// event of the resource, the labels of the vm select it.
+func (c *Controller) resolveTowerChange(ctx context.Context, kind datamodel.ResourceType, id string) *towerChange {
+	log := ctrl.LoggerFrom(ctx).WithValues("type", kind, "id", id)
+	change := &towerChange{vms: sets.New[string]()}
+	switch kind {
+	case datamodel.TypeVM:
+		change.vms.Insert(id)
+		vm := &datamodel.VM{}
+		exists, err := c.towerCli.Get(ctx, id, vm)
+		if err != nil {
+			log.Error(err, "Failed to get vm from tower")
+			change.all = true
+		} else if exists {
+			change.labels = vm.Labels
+		}
+	case datamodel.TypeVMNic:
+		vnic := &datamodel.VMNic{}
+		exists, err := c.towerCli.Get(ctx, id, vnic)
+		if err != nil {
+			log.Error(err, "Failed to get vnic from tower")
+		}
+		if err != nil || !exists || vnic.VM.ID == "" {
+			change.all = true
+			break
+		}
+		change.vms.Insert(vnic.VM.ID)
+		change.labels = vnic.VM.Labels
+	case datamodel.TypeLabel:
+		label := &datamodel.Label{}
+		exists, err := c.towerCli.Get(ctx, id, label)
+		if err != nil {
+			log.Error(err, "Failed to get label from tower")
+		}
+		if err != nil || !exists {
+			change.allLabels = true
+			break
+		}
+		change.labels = []datamodel.Label{*label}
+	default:
+		change.all = true
+	}
+	return change
+}
+
+// affects reports whether the rule selects the changed vms, by id or the
+// selected macs, or selects the changed labels.
+func (t *towerChange) affects(r *v1alpha1.Rule) bool {
+	sel := r.Spec.Match.Selector
+	if t.all || (t.allLabels && len(sel.TowerLabels) != 0) {
+		return true
+	}
+	for _, id := range sel.TowerVMs {
+		if t.vms.Has(id) {
+			return true
+		}
+	}
+	for _, m := range r.Status.SelectedMacs {
+		if id, ok := strings.CutPrefix(m.Source, towerVMSourcePrefix); ok && t.vms.Has(id) {
+			return true
+		}
+	}
+	for _, tl := range sel.TowerLabels {
+		for _, l := range t.labels {
+			if tl.Key == l.Key && (tl.Value == "" || tl.Value == l.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rulesToRequests returns the rules and cluster rules with selector for which filter is true.
+func (c *Controller) rulesToRequests(ctx context.Context, filter func(*v1alpha1.Rule) bool) []reconcile.Request {
+	rules := &v1alpha1.RuleList{}
+	if err := c.k8scli.List(ctx, rules); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list rules")
+		return nil
+	}
+	clusterRules := &v1alpha1.ClusterRuleList{}
+	if err := c.k8scli.List(ctx, clusterRules); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list cluster rules")
+		return nil
+	}
+	candidates := make([]*v1alpha1.Rule, 0, len(rules.Items)+len(clusterRules.Items))
+	for i := range rules.Items {
+		candidates = append(candidates, &rules.Items[i])
+	}
+	for i := range clusterRules.Items {
+		candidates = append(candidates, clusterRules.Items[i].AsRule())
+	}
+
+	var reqs []reconcile.Request
+	for _, r := range candidates {
+		if r.Spec.Match.Selector != nil && filter(r) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: r.Namespace, Name: r.Name}})
+		}
+	}
+	return reqs
+}
+
+func newPodMetadataListObject() *metav1.PartialObjectMetadataList {
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(podGVK.GroupVersion().WithKind(podGVK.Kind + "List"))
+	return list
+}
+
+func newVMIListObject() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(vmiGVK.GroupVersion().WithKind(vmiGVK.Kind + "List"))
+	return list
+}
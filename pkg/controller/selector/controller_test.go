@@ -0,0 +1,349 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	gomonkey "github.com/agiledragon/gomonkey/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+	"github.com/everoute/trafficredirect/pkg/tower/client"
+	"github.com/everoute/trafficredirect/pkg/tower/datamodel"
+)
+
+func TestSelector(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Selector Suite")
+}
+
+func newTestPod(ns, name string, labels map[string]string, macs ...string) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Labels: labels}}
+	if len(macs) != 0 {
+		status := "["
+		for i, mac := range macs {
+			if i != 0 {
+				status += ","
+			}
+			status += fmt.Sprintf(`{"name":"net%d","mac":"%s"}`, i, mac)
+		}
+		pod.Annotations = map[string]string{NetworkStatusAnnotation: status + "]"}
+	}
+	return pod
+}
+
+var _ = Describe("Rule selector controller", func() {
+	var (
+		ctx      context.Context
+		c        *Controller
+		towerCli *client.Client
+		patches  *gomonkey.Patches
+		key      = types.NamespacedName{Namespace: "default", Name: "r1"}
+	)
+
+	newRule := func(sel *v1alpha1.VMSelector) *v1alpha1.Rule {
+		return &v1alpha1.Rule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, Generation: 1},
+			Spec: v1alpha1.RuleSpec{
+				Direct: v1alpha1.Egress,
+				Match:  v1alpha1.RuleMatch{Selector: sel},
+			},
+		}
+	}
+
+	setup := func(objs ...k8sclient.Object) {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		towerCli = &client.Client{}
+		c = &Controller{
+			towerCli: towerCli,
+			k8scli: fake.NewClientBuilder().WithScheme(scheme).
+				WithStatusSubresource(&v1alpha1.Rule{}, &v1alpha1.ClusterRule{}).WithObjects(objs...).Build(),
+		}
+	}
+
+	reconcileAndGet := func() (*v1alpha1.Rule, error) {
+		_, err := c.handle(ctx, ctrl.Request{NamespacedName: key})
+		rule := &v1alpha1.Rule{}
+		Expect(c.k8scli.Get(ctx, key, rule)).To(Succeed())
+		return rule, err
+	}
+
+	patchTower := func(vms map[string][]string, labels map[string][]string, err error) {
+		toVM := func(id string) datamodel.VM {
+			vm := datamodel.VM{ID: id}
+			for _, mac := range vms[id] {
+				vm.VMNics = append(vm.VMNics, datamodel.VMNic{MacAddress: mac})
+			}
+			return vm
+		}
+		patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "Get",
+			func(_ *client.Client, _ context.Context, id string, obj datamodel.GqlType) (bool, error) {
+				if err != nil {
+					return false, err
+				}
+				if _, ok := vms[id]; !ok {
+					return false, nil
+				}
+				*obj.(*datamodel.VM) = toVM(id)
+				return true, nil
+			},
+		)
+		patches.ApplyMethod(reflect.TypeOf(towerCli), "List",
+			func(_ *client.Client, _ context.Context, where string, obj datamodel.GqlListType) error {
+				if err != nil {
+					return err
+				}
+				list := obj.(*datamodel.Labels)
+				label := datamodel.Label{}
+				for _, id := range labels[where] {
+					label.VMs = append(label.VMs, toVM(id))
+				}
+				*list = append(*list, label)
+				return nil
+			},
+		)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		if patches != nil {
+			patches.Reset()
+			patches = nil
+		}
+	})
+
+	It("should select macs of pods by label selector", func() {
+		setup(
+			newRule(&v1alpha1.VMSelector{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}),
+			newTestPod("default", "web-1", map[string]string{"app": "web"}, "00:11:22:33:44:55", "00:11:22:33:44:AA"),
+			newTestPod("default", "web-2", map[string]string{"app": "web"}),
+			newTestPod("default", "db-1", map[string]string{"app": "db"}, "00:11:22:33:44:66"),
+			newTestPod("other", "web-3", map[string]string{"app": "web"}, "00:11:22:33:44:77"),
+		)
+		rule, err := reconcileAndGet()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rule.Status.SelectedMacs).To(Equal([]v1alpha1.SelectedMac{
+			{Mac: "00:11:22:33:44:55", Source: "pod/web-1"},
+			{Mac: "00:11:22:33:44:aa", Source: "pod/web-1"},
+		}))
+		Expect(meta.IsStatusConditionTrue(rule.Status.Conditions, v1alpha1.RuleConditionSelected)).To(BeTrue())
+	})
+
+	It("should select macs of pods in all namespaces for cluster rule", func() {
+		cr := &v1alpha1.ClusterRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "cr1", Generation: 1},
+			Spec:       newRule(&v1alpha1.VMSelector{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}).Spec,
+		}
+		setup(
+			cr,
+			newTestPod("default", "web-1", map[string]string{"app": "web"}, "00:11:22:33:44:55"),
+			newTestPod("other", "web-2", map[string]string{"app": "web"}, "00:11:22:33:44:66"),
+		)
+		crKey := types.NamespacedName{Name: "cr1"}
+		_, err := c.handle(ctx, ctrl.Request{NamespacedName: crKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.k8scli.Get(ctx, crKey, cr)).To(Succeed())
+		Expect(cr.Status.SelectedMacs).To(Equal([]v1alpha1.SelectedMac{
+			{Mac: "00:11:22:33:44:55", Source: "pod/web-1"},
+			{Mac: "00:11:22:33:44:66", Source: "pod/web-2"},
+		}))
+
+		reqs := c.workloadToRules(ctx, newTestPod("other", "web-2", nil))
+		Expect(reqs).To(ConsistOf(ctrl.Request{NamespacedName: crKey}))
+	})
+
+	It("should select macs of tower vms and labels", func() {
+		setup(newRule(&v1alpha1.VMSelector{
+			TowerVMs:    []string{"vm1", "none"},
+			TowerLabels: []v1alpha1.TowerLabel{{Key: "env", Value: "prod"}},
+		}))
+		patchTower(
+			map[string][]string{"vm1": {"00:00:00:00:00:01"}, "vm2": {"00:00:00:00:00:02", "00:00:00:00:00:03"}},
+			map[string][]string{datamodel.LabelWhere("env", "prod"): {"vm1", "vm2"}},
+			nil,
+		)
+		rule, err := reconcileAndGet()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rule.Status.SelectedMacs).To(Equal([]v1alpha1.SelectedMac{
+			{Mac: "00:00:00:00:00:01", Source: "tower-vm/vm1"},
+			{Mac: "00:00:00:00:00:02", Source: "tower-vm/vm2"},
+			{Mac: "00:00:00:00:00:03", Source: "tower-vm/vm2"},
+		}))
+	})
+
+	It("should keep selected macs when failed to query tower", func() {
+		r := newRule(&v1alpha1.VMSelector{TowerVMs: []string{"vm1"}})
+		r.Status.SelectedMacs = []v1alpha1.SelectedMac{{Mac: "00:00:00:00:00:01", Source: "tower-vm/vm1"}}
+		setup(r)
+		patchTower(nil, nil, fmt.Errorf("tower unavailable"))
+
+		rule, err := reconcileAndGet()
+		Expect(err).To(HaveOccurred())
+		Expect(rule.Status.SelectedMacs).To(HaveLen(1))
+		cond := meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionSelected)
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(v1alpha1.ReasonSelectFailed))
+		Expect(cond.Message).To(ContainSubstring("tower unavailable"))
+	})
+
+	It("should clean selected macs when selector removed", func() {
+		r := newRule(nil)
+		r.Spec.Match.SrcMac = "00:00:00:00:00:01"
+		r.Status.SelectedMacs = []v1alpha1.SelectedMac{{Mac: "00:00:00:00:00:01"}}
+		meta.SetStatusCondition(&r.Status.Conditions, metav1.Condition{Type: v1alpha1.RuleConditionSelected, Status: metav1.ConditionTrue, Reason: v1alpha1.ReasonSelected})
+		setup(r)
+
+		rule, err := reconcileAndGet()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rule.Status.SelectedMacs).To(BeEmpty())
+		Expect(meta.FindStatusCondition(rule.Status.Conditions, v1alpha1.RuleConditionSelected)).To(BeNil())
+	})
+
+	It("should map workloads and tower events to rules", func() {
+		byLabel := newRule(&v1alpha1.VMSelector{LabelSelector: &metav1.LabelSelector{}})
+		byTower := newRule(&v1alpha1.VMSelector{TowerVMs: []string{"vm1"}})
+		byTower.Name = "r2"
+		literal := newRule(nil)
+		literal.Name = "r3"
+		setup(byLabel, byTower, literal)
+
+		reqs := c.workloadToRules(ctx, newTestPod("default", "web-1", nil))
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].Name).To(Equal("r1"))
+		Expect(c.workloadToRules(ctx, newTestPod("other", "web-1", nil))).To(BeEmpty())
+	})
+
+	It("should map tower events to the rules selecting the changed resources", func() {
+		byVM := newRule(&v1alpha1.VMSelector{TowerVMs: []string{"vm1"}})
+		byVM.Name = "r2"
+		byLabel := newRule(&v1alpha1.VMSelector{TowerLabels: []v1alpha1.TowerLabel{{Key: "env", Value: "prod"}}})
+		byLabel.Name = "r3"
+		byLabel.Status.SelectedMacs = []v1alpha1.SelectedMac{{Mac: "00:00:00:00:00:03", Source: "tower-vm/vm3"}}
+		byLabelKey := newRule(&v1alpha1.VMSelector{TowerLabels: []v1alpha1.TowerLabel{{Key: "app"}}})
+		byLabelKey.Name = "r4"
+		setup(newRule(&v1alpha1.VMSelector{LabelSelector: &metav1.LabelSelector{}}), byVM, byLabel, byLabelKey)
+		patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "Get",
+			func(_ *client.Client, _ context.Context, id string, obj datamodel.GqlType) (bool, error) {
+				switch o := obj.(type) {
+				case *datamodel.VM:
+					*o = datamodel.VM{ID: id}
+					if id == "vm2" {
+						o.Labels = []datamodel.Label{{Key: "app", Value: "web"}}
+					}
+					return true, nil
+				case *datamodel.VMNic:
+					o.VM = datamodel.VM{ID: "vm3"}
+					return id == "nic3", nil
+				case *datamodel.Label:
+					o.Key, o.Value = "env", "prod"
+					return id == "label1", nil
+				}
+				return false, nil
+			},
+		)
+
+		towerEvent := func(kind datamodel.ResourceType, id string) []string {
+			obj := &metav1.PartialObjectMetadata{
+				TypeMeta:   metav1.TypeMeta{Kind: string(kind)},
+				ObjectMeta: metav1.ObjectMeta{Name: id},
+			}
+			var names []string
+			for _, req := range c.towerToRules(ctx, obj) {
+				names = append(names, req.Name)
+			}
+			sort.Strings(names)
+			return names
+		}
+		Expect(towerEvent(datamodel.TypeVM, "vm1")).To(Equal([]string{"r2"}))
+		Expect(towerEvent(datamodel.TypeVM, "vm2")).To(Equal([]string{"r4"}))
+		Expect(towerEvent(datamodel.TypeVM, "vm4")).To(BeEmpty())
+		Expect(towerEvent(datamodel.TypeVMNic, "nic3")).To(Equal([]string{"r3"}))
+		Expect(towerEvent(datamodel.TypeVMNic, "deleted")).To(Equal([]string{"r2", "r3", "r4"}))
+		Expect(towerEvent(datamodel.TypeLabel, "label1")).To(Equal([]string{"r3"}))
+		Expect(towerEvent(datamodel.TypeLabel, "deleted")).To(Equal([]string{"r3", "r4"}))
+	})
+
+	It("should resync all rules of tower selector when tower events are dropped", func() {
+		byVM := newRule(&v1alpha1.VMSelector{TowerVMs: []string{"vm1"}})
+		byVM.Name = "r2"
+		setup(newRule(&v1alpha1.VMSelector{LabelSelector: &metav1.LabelSelector{}}), byVM)
+		c.towerEvents = make(chan event.GenericEvent, 1)
+		vmType := string(datamodel.TypeVM)
+		crcEvent := func(id string) *models.ResourceChangeEvent {
+			return &models.ResourceChangeEvent{ResourceType: &vmType, ResourceID: &id}
+		}
+
+		c.crcHandler(crcEvent("vm2"))
+		c.crcHandler(crcEvent("vm3"))
+		c.crcHandler(crcEvent("vm4"))
+		Expect(c.resyncPending.Load()).To(BeTrue())
+		Expect((<-c.towerEvents).Object.GetName()).To(Equal("vm2"))
+
+		resync := <-c.towerEvents
+		Expect(resync.Object.GetObjectKind().GroupVersionKind().Kind).To(BeEmpty())
+		Expect(c.towerToRules(ctx, resync.Object)).To(Equal([]reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "default", Name: "r2"}}}))
+		Expect(c.resyncPending.Load()).To(BeFalse())
+		Consistently(c.towerEvents).ShouldNot(Receive())
+	})
+
+	It("should parse macs of vmi", func() {
+		vmi := newVMIObject()
+		Expect(unstructured.SetNestedSlice(vmi.Object, []interface{}{
+			map[string]interface{}{"name": "default", "mac": "00:11:22:33:44:55"},
+			map[string]interface{}{"name": "secondary"},
+		}, "status", "interfaces")).To(Succeed())
+		Expect(vmiMacs(vmi)).To(Equal([]string{"00:11:22:33:44:55"}))
+	})
+
+	It("should only watch label and mac changes of workloads", func() {
+		p := workloadChangedPredicate(vmiMacs)
+		oldVMI := newVMIObject()
+		oldVMI.SetLabels(map[string]string{"app": "web"})
+		Expect(unstructured.SetNestedSlice(oldVMI.Object, []interface{}{
+			map[string]interface{}{"name": "default", "mac": "00:11:22:33:44:55"},
+		}, "status", "interfaces")).To(Succeed())
+
+		newVMI := oldVMI.DeepCopy()
+		newVMI.SetAnnotations(map[string]string{"kubevirt.io/latest-observed-api-version": "v1"})
+		Expect(unstructured.SetNestedField(newVMI.Object, "Running", "status", "phase")).To(Succeed())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldVMI, ObjectNew: newVMI})).To(BeFalse())
+
+		newVMI.SetLabels(map[string]string{"app": "db"})
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldVMI, ObjectNew: newVMI})).To(BeTrue())
+
+		newVMI = oldVMI.DeepCopy()
+		Expect(unstructured.SetNestedSlice(newVMI.Object, []interface{}{
+			map[string]interface{}{"name": "default", "mac": "00:11:22:33:44:66"},
+		}, "status", "interfaces")).To(Succeed())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldVMI, ObjectNew: newVMI})).To(BeTrue())
+
+		p = workloadChangedPredicate(podMacs)
+		oldPod := newTestPod("default", "web-1", nil, "00:11:22:33:44:55")
+		newPod := newTestPod("default", "web-1", nil, "00:11:22:33:44:55")
+		newPod.Annotations["other"] = "changed"
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod})).To(BeFalse())
+		newPod = newTestPod("default", "web-1", nil, "00:11:22:33:44:66")
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod})).To(BeTrue())
+	})
+})
@@ -0,0 +1,108 @@
+package selector
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// NetworkStatusAnnotation is set on pods by multus with the interfaces of the pod.
+	NetworkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+)
+
+var (
+	vmiGVK = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachineInstance"}
+	podGVK = corev1.SchemeGroupVersion.WithKind("Pod")
+)
+
+type networkStatus struct {
+	Name      string `json:"name"`
+	Interface string `json:"interface,omitempty"`
+	Mac       string `json:"mac,omitempty"`
+}
+
+func newVMIObject() *unstructured.Unstructured {
+	vmi := &unstructured.Unstructured{}
+	vmi.SetGroupVersionKind(vmiGVK)
+	return vmi
+}
+
+// newPodMetadataObject returns the metadata of pods, the labels and the
+// annotations are all the selector needs of pods.
+func newPodMetadataObject() *metav1.PartialObjectMetadata {
+	pod := &metav1.PartialObjectMetadata{}
+	pod.SetGroupVersionKind(podGVK)
+	return pod
+}
+
+// podMacs returns the macs of the pod interfaces reported by multus.
+func podMacs(pod k8sclient.Object) []string {
+	raw, ok := pod.GetAnnotations()[NetworkStatusAnnotation]
+	if !ok {
+		return nil
+	}
+	var status []networkStatus
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		return nil
+	}
+	macs := make([]string, 0, len(status))
+	for _, s := range status {
+		if s.Mac != "" {
+			macs = append(macs, s.Mac)
+		}
+	}
+	return macs
+}
+
+// vmiMacs returns the macs in status.interfaces of the kubevirt VMI.
+func vmiMacs(vmi *unstructured.Unstructured) []string {
+	interfaces, _, _ := unstructured.NestedSlice(vmi.Object, "status", "interfaces")
+	macs := make([]string, 0, len(interfaces))
+	for _, i := range interfaces {
+		iface, ok := i.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if mac, ok := iface["mac"].(string); ok && mac != "" {
+			macs = append(macs, mac)
+		}
+	}
+	return macs
+}
+
+// workloadChangedPredicate fires on the updates of workloads changing the labels
+// or the macs, macs is podMacs or vmiMacs of the workload object.
+func workloadChangedPredicate[T k8sclient.Object](macs func(T) []string) predicate.Predicate {
+	macsChanged := predicate.Funcs{UpdateFunc: func(e event.UpdateEvent) bool {
+		oldObj, ok := e.ObjectOld.(T)
+		if !ok {
+			return false
+		}
+		newObj, ok := e.ObjectNew.(T)
+		if !ok {
+			return false
+		}
+		return !reflect.DeepEqual(macs(oldObj), macs(newObj))
+	}}
+	return predicate.Or(predicate.LabelChangedPredicate{}, macsChanged)
+}
+
+// addMac adds the mac in canonical form, invalid macs are ignored.
+func addMac(macs map[string]string, mac, source string) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) != 6 {
+		return
+	}
+	if _, ok := macs[hw.String()]; !ok {
+		macs[hw.String()] = source
+	}
+}
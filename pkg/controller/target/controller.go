@@ -0,0 +1,152 @@
+package target
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+	"github.com/everoute/trafficredirect/pkg/source"
+)
+
+// Controller probes the endpoints of RedirectTargets and publishes the
+// available endpoints in status.
+type Controller struct {
+	k8scli  k8sclient.Client
+	targetW controller.Controller
+	prober  Prober
+
+	lock sync.Mutex
+	// consecutive probe failures of endpoints
+	failures map[types.NamespacedName]map[string]int32
+}
+
+func NewController(mgr ctrl.Manager, prober Prober) *Controller {
+	c := &Controller{
+		k8scli:   mgr.GetClient(),
+		prober:   prober,
+		failures: make(map[types.NamespacedName]map[string]int32),
+	}
+
+	var err error
+	c.targetW, err = controller.NewUnmanaged("redirect-target", mgr, controller.Options{Reconciler: reconcile.Func(c.handle)})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to new redirect target controller")
+		os.Exit(1)
+	}
+	// probe is driven by requeue, status updates needn't trigger it
+	err = c.targetW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.RedirectTarget{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch redirect target")
+		os.Exit(1)
+	}
+
+	return c
+}
+
+func (c *Controller) Start(ctx context.Context) error {
+	return c.targetW.Start(ctx)
+}
+
+func (c *Controller) handle(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(4).Info("Reconciling redirect target start")
+	defer log.V(4).Info("Reconciling redirect target end")
+
+	target := &v1alpha1.RedirectTarget{}
+	if err := c.k8scli.Get(ctx, req.NamespacedName, target); err != nil {
+		if errors.IsNotFound(err) {
+			c.lock.Lock()
+			delete(c.failures, req.NamespacedName)
+			c.lock.Unlock()
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get redirect target")
+		return ctrl.Result{}, err
+	}
+
+	status := c.probe(ctx, target)
+	if !equality.Semantic.DeepEqual(&target.Status, status) {
+		target.Status = *status
+		if err := c.k8scli.Status().Update(ctx, target); err != nil {
+			log.Error(err, "Failed to update redirect target status")
+			return ctrl.Result{}, err
+		}
+		log.Info("Success to update redirect target status", "available", status.AvailableCount)
+	}
+
+	if target.Spec.Probe == nil {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: time.Duration(target.Spec.Probe.PeriodSeconds) * time.Second}, nil
+}
+
+func (c *Controller) probe(ctx context.Context, target *v1alpha1.RedirectTarget) *v1alpha1.RedirectTargetStatus {
+	log := ctrl.LoggerFrom(ctx)
+	key := types.NamespacedName{Namespace: target.Namespace, Name: target.Name}
+	oldStatus := make(map[string]v1alpha1.TargetEndpointStatus, len(target.Status.Endpoints))
+	for _, s := range target.Status.Endpoints {
+		oldStatus[s.Name] = s
+	}
+
+	// endpoints are probed without the lock, slow probes of a target don't
+	// block the others
+	var probeErrs map[string]error
+	if p := target.Spec.Probe; p != nil {
+		probeErrs = make(map[string]error, len(target.Spec.Endpoints))
+		for i := range target.Spec.Endpoints {
+			ep := &target.Spec.Endpoints[i]
+			if err := c.prober.Probe(ctx, ep, time.Duration(p.TimeoutSeconds)*time.Second); err != nil {
+				log.V(2).Info("Failed to probe endpoint", "endpoint", ep.Name, "err", err)
+				probeErrs[ep.Name] = err
+			}
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	oldFailures := c.failures[key]
+	failures := make(map[string]int32, len(target.Spec.Endpoints))
+
+	status := &v1alpha1.RedirectTargetStatus{ObservedGeneration: target.Generation}
+	for i := range target.Spec.Endpoints {
+		ep := &target.Spec.Endpoints[i]
+		old, seen := oldStatus[ep.Name]
+		epStatus := v1alpha1.TargetEndpointStatus{Name: ep.Name, Available: true, LastTransitionTime: old.LastTransitionTime}
+
+		if p := target.Spec.Probe; p != nil {
+			failures[ep.Name] = oldFailures[ep.Name]
+			if err := probeErrs[ep.Name]; err != nil {
+				failures[ep.Name]++
+				epStatus.Message = err.Error()
+			} else {
+				failures[ep.Name] = 0
+			}
+			// an available endpoint tolerates failures up to the threshold
+			epStatus.Available = failures[ep.Name] == 0 || (seen && old.Available && failures[ep.Name] < p.FailureThreshold)
+		}
+
+		if !seen || old.Available != epStatus.Available {
+			epStatus.LastTransitionTime = metav1.Now()
+		}
+		status.Endpoints = append(status.Endpoints, epStatus)
+		if epStatus.Available {
+			status.AvailableEndpoints = append(status.AvailableEndpoints, *ep)
+			status.AvailableCount++
+		}
+	}
+	c.failures[key] = failures
+	return status
+}
@@ -0,0 +1,152 @@
+package target
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+)
+
+func TestTarget(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Target Suite")
+}
+
+// fakeProber fails the endpoints in the down set
+type fakeProber struct {
+	down map[string]bool
+	// onProbe is called on probing an endpoint when it's set
+	onProbe func()
+}
+
+func (p *fakeProber) Probe(_ context.Context, ep *v1alpha1.TargetEndpoint, _ time.Duration) error {
+	if p.onProbe != nil {
+		p.onProbe()
+	}
+	if p.down[ep.Name] {
+		return fmt.Errorf("endpoint %s is down", ep.Name)
+	}
+	return nil
+}
+
+var _ = Describe("RedirectTarget controller", func() {
+	var (
+		ctx    context.Context
+		c      *Controller
+		prober *fakeProber
+		key    = types.NamespacedName{Namespace: "default", Name: "dpi"}
+	)
+
+	newTarget := func(probe *v1alpha1.TargetProbe) *v1alpha1.RedirectTarget {
+		return &v1alpha1.RedirectTarget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, Generation: 1},
+			Spec: v1alpha1.RedirectTargetSpec{
+				Endpoints: []v1alpha1.TargetEndpoint{
+					{Name: "ep1", Mac: "00:00:00:00:00:01", Weight: 1},
+					{Name: "ep2", Mac: "00:00:00:00:00:02", Weight: 1},
+				},
+				Probe: probe,
+			},
+		}
+	}
+
+	setup := func(t *v1alpha1.RedirectTarget) {
+		scheme := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		prober = &fakeProber{down: map[string]bool{}}
+		c = &Controller{
+			k8scli: fake.NewClientBuilder().WithScheme(scheme).
+				WithStatusSubresource(&v1alpha1.RedirectTarget{}).WithObjects(t).Build(),
+			prober:   prober,
+			failures: make(map[types.NamespacedName]map[string]int32),
+		}
+	}
+
+	reconcileAndGet := func() (ctrl.Result, *v1alpha1.RedirectTarget) {
+		res, err := c.handle(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		t := &v1alpha1.RedirectTarget{}
+		Expect(c.k8scli.Get(ctx, key, t)).To(Succeed())
+		return res, t
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should publish all endpoints without probe", func() {
+		setup(newTarget(nil))
+		prober.down["ep1"] = true
+		res, t := reconcileAndGet()
+		Expect(res.RequeueAfter).To(BeZero())
+		Expect(t.Status.AvailableCount).To(Equal(int32(2)))
+		Expect(t.Status.AvailableEndpoints).To(HaveLen(2))
+	})
+
+	It("should remove endpoint after failure threshold", func() {
+		setup(newTarget(&v1alpha1.TargetProbe{PeriodSeconds: 5, TimeoutSeconds: 1, FailureThreshold: 2}))
+		res, t := reconcileAndGet()
+		Expect(res.RequeueAfter).To(Equal(5 * time.Second))
+		Expect(t.Status.AvailableCount).To(Equal(int32(2)))
+
+		prober.down["ep1"] = true
+		_, t = reconcileAndGet()
+		Expect(t.Status.AvailableCount).To(Equal(int32(2)))
+		Expect(t.Status.Endpoints[0].Message).To(ContainSubstring("ep1 is down"))
+
+		_, t = reconcileAndGet()
+		Expect(t.Status.AvailableCount).To(Equal(int32(1)))
+		Expect(t.Status.AvailableEndpoints[0].Name).To(Equal("ep2"))
+		Expect(t.Status.Endpoints[0].Available).To(BeFalse())
+
+		delete(prober.down, "ep1")
+		_, t = reconcileAndGet()
+		Expect(t.Status.AvailableCount).To(Equal(int32(2)))
+		Expect(t.Status.Endpoints[0].Message).To(BeEmpty())
+	})
+
+	It("should not publish a new endpoint failed to probe", func() {
+		setup(newTarget(&v1alpha1.TargetProbe{PeriodSeconds: 5, TimeoutSeconds: 1, FailureThreshold: 3}))
+		prober.down["ep2"] = true
+		_, t := reconcileAndGet()
+		Expect(t.Status.AvailableCount).To(Equal(int32(1)))
+		Expect(t.Status.AvailableEndpoints[0].Name).To(Equal("ep1"))
+	})
+
+	It("should not hold the lock while probing", func() {
+		setup(newTarget(&v1alpha1.TargetProbe{PeriodSeconds: 5, TimeoutSeconds: 1, FailureThreshold: 3}))
+		var locked []bool
+		prober.onProbe = func() {
+			ok := c.lock.TryLock()
+			if ok {
+				c.lock.Unlock()
+			}
+			locked = append(locked, !ok)
+		}
+		reconcileAndGet()
+		Expect(locked).To(Equal([]bool{false, false}))
+	})
+
+	It("should clean failures of deleted target", func() {
+		setup(newTarget(&v1alpha1.TargetProbe{PeriodSeconds: 5, TimeoutSeconds: 1, FailureThreshold: 3}))
+		reconcileAndGet()
+		Expect(c.failures).To(HaveKey(key))
+
+		t := &v1alpha1.RedirectTarget{}
+		Expect(c.k8scli.Get(ctx, key, t)).To(Succeed())
+		Expect(c.k8scli.Delete(ctx, t)).To(Succeed())
+		_, err := c.handle(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.failures).NotTo(HaveKey(key))
+	})
+})
@@ -0,0 +1,31 @@
+package target
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+)
+
+// Prober checks the health of a RedirectTarget endpoint.
+type Prober interface {
+	// Probe returns nil when the endpoint is available.
+	Probe(ctx context.Context, ep *v1alpha1.TargetEndpoint, timeout time.Duration) error
+}
+
+// TCPProber connects to the endpoint address, endpoint without address is
+// always available.
+type TCPProber struct{}
+
+func (TCPProber) Probe(ctx context.Context, ep *v1alpha1.TargetEndpoint, timeout time.Duration) error {
+	if ep.Address == "" {
+		return nil
+	}
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, "tcp", ep.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/everoute/graphc/pkg/crcwatch"
@@ -13,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
@@ -21,9 +23,11 @@ import (
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/everoute/trafficredirect/api/trafficredirect/v1alpha1"
+	"github.com/everoute/trafficredirect/pkg/config"
 	"github.com/everoute/trafficredirect/pkg/constants"
 	ilog "github.com/everoute/trafficredirect/pkg/log"
 	"github.com/everoute/trafficredirect/pkg/source"
@@ -35,21 +39,34 @@ const (
 	CrcChanSize = 100
 )
 
+// resyncBackoff retries the resync on start up to about 2 minutes apart.
+var resyncBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Jitter: 0.1, Steps: 8}
+
 type Controller struct {
-	towerCli  *client.Client
-	k8scli    k8sclient.Client
-	ruleW     controller.Controller
-	crcW      *crcwatch.Watch
-	syncCache cache.Cache
+	towerCli   *client.Client
+	k8scli     k8sclient.Client
+	ruleW      controller.Controller
+	exemptionW controller.Controller
+	crcW       *crcwatch.Watch
+	syncCache  cache.Cache
+	// create a single rule of direct both per vnic
+	bidirectional bool
+	// failure policy of the vnic rules, the tower vm label overrides it
+	failurePolicy v1alpha1.FailurePolicy
+	// interval of the full resync with tower, only resync on start when it's zero
+	resyncInterval time.Duration
 
 	queue workqueue.RateLimitingInterface
 }
 
 func NewController(mgr ctrl.Manager, towerCli *client.Client) *Controller {
 	c := &Controller{
-		towerCli: towerCli,
-		k8scli:   mgr.GetClient(),
-		queue:    workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		towerCli:       towerCli,
+		k8scli:         mgr.GetClient(),
+		bidirectional:  config.Config.VnicBidirectionalRule,
+		failurePolicy:  v1alpha1.FailurePolicy(config.Config.VnicFailurePolicy),
+		resyncInterval: config.Config.VnicResyncInterval,
+		queue:          workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 	}
 
 	var err error
@@ -58,14 +75,26 @@ func NewController(mgr ctrl.Manager, towerCli *client.Client) *Controller {
 		ctrl.Log.Error(err, "Failed to new rule controller")
 		os.Exit(1)
 	}
-	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.Rule{}), &handler.EnqueueRequestForObject{})
+	// status is reported by agents frequently, only spec changes need to sync with tower
+	err = c.ruleW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.Rule{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
 	if err != nil {
 		ctrl.Log.Error(err, "Failed to watch rule")
 		os.Exit(1)
 	}
+	c.exemptionW, err = controller.NewUnmanaged("redirect-exemption", mgr, controller.Options{Reconciler: reconcile.Func(c.exemptionHandle)})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to new redirect exemption controller")
+		os.Exit(1)
+	}
+	err = c.exemptionW.Watch(source.Kind(mgr.GetCache(), &v1alpha1.RedirectExemption{}), &handler.EnqueueRequestForObject{}, predicate.GenerationChangedPredicate{})
+	if err != nil {
+		ctrl.Log.Error(err, "Failed to watch redirect exemption")
+		os.Exit(1)
+	}
 	c.syncCache = mgr.GetCache()
 
-	c.crcW, err = client.NewCRCWatch([]datamodel.ResourceType{datamodel.TypeVMNic})
+	// the labels of vms set the failure policy of the vnic rules
+	c.crcW, err = client.NewCRCWatch([]datamodel.ResourceType{datamodel.TypeVMNic, datamodel.TypeVM, datamodel.TypeLabel})
 	if err != nil {
 		ctrl.Log.Error(err, "Failed to new crc watch")
 		os.Exit(1)
@@ -88,11 +117,20 @@ func (c *Controller) Start(stopCtx context.Context) error {
 		return c.ruleW.Start(ctx)
 	})
 
+	g.Go(func() error {
+		return c.exemptionW.Start(ctx)
+	})
+
 	g.Go(func() error {
 		c.crcW.Start(ctx.Done())
 		return nil
 	})
 
+	g.Go(func() error {
+		c.resyncLoop(ctx)
+		return nil
+	})
+
 	g.Go(func() error {
 		wait.Until(
 			graphcinformer.ReconcileWorker(ctx, "rule-sync", c.queue, c.handle),
@@ -118,13 +156,22 @@ func (c *Controller) crcHandler(e *models.ResourceChangeEvent) {
 		return
 	}
 
-	if *e.ResourceType != string(datamodel.TypeVMNic) {
+	switch t := datamodel.ResourceType(*e.ResourceType); t {
+	case datamodel.TypeVMNic:
+		c.queue.Add(*e.ResourceID)
+	case datamodel.TypeVM, datamodel.TypeLabel:
+		c.queue.Add(towerResourceKey(t, *e.ResourceID))
+	default:
 		log.Info("Unexpected resource type for crc event, skip", "event type", *e.ResourceType)
 		return
 	}
-
 	log.V(4).Info("Received crc event", "type", *e.ResourceType, "id", *e.ResourceID, "action", *e.Action)
-	c.queue.Add(*e.ResourceID)
+}
+
+// towerResourceKey is the queue key of a tower vm or label, the key of a vnic
+// is its id.
+func towerResourceKey(t datamodel.ResourceType, id string) string {
+	return string(t) + "/" + id
 }
 
 func (c *Controller) ruleHandle(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -142,7 +189,113 @@ func (c *Controller) ruleHandle(ctx context.Context, req ctrl.Request) (ctrl.Res
 	return ctrl.Result{}, nil
 }
 
-func (c *Controller) handle(ctx context.Context, vnicID string) error {
+// exemptionHandle resyncs the vnics an exemption change may affect: vnics of
+// existing rules may become exempt, and dpi enabled vnics may become not exempt.
+func (c *Controller) exemptionHandle(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(4).Info("Reconciling redirect exemption start")
+	defer log.V(4).Info("Reconciling redirect exemption end")
+
+	ruleVnics, dpiVnics, err := c.listVnics(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for vnicID := range ruleVnics.Union(dpiVnics) {
+		c.queue.Add(vnicID)
+	}
+	log.V(2).Info("Success to add vnics to queue from redirect exemption", "ruleVnics", ruleVnics.Len(), "dpiVnics", dpiVnics.Len())
+	return ctrl.Result{}, nil
+}
+
+// resync adds the vnics out of sync to the queue, they are the vnics of rules
+// not dpi enabled in tower, e.g. deleted while the controller is down, and the
+// dpi enabled vnics not exempt without rules, e.g. whose crc events are missed.
+func (c *Controller) resync(ctx context.Context) error {
+	log := ctrl.Log.WithName("vnic-resync")
+	ruleVnics, dpiVnics, err := c.listVnics(ctx)
+	if err != nil {
+		log.Error(err, "Failed to resync vnics with tower")
+		return err
+	}
+	diff := ruleVnics.SymmetricDifference(dpiVnics)
+	for vnicID := range diff {
+		c.queue.Add(vnicID)
+	}
+	log.Info("Success to resync vnics with tower", "ruleVnics", ruleVnics.Len(), "dpiVnics", dpiVnics.Len(), "outOfSync", diff.Len())
+	return nil
+}
+
+// resyncLoop retries the resync on start until it succeeds, then resyncs
+// every resyncInterval.
+func (c *Controller) resyncLoop(ctx context.Context) {
+	resynced := func(ctx context.Context) (bool, error) {
+		return c.resync(ctx) == nil, nil
+	}
+	for {
+		err := wait.ExponentialBackoffWithContext(ctx, resyncBackoff, resynced)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+	if c.resyncInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.resyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.resync(ctx)
+		}
+	}
+}
+
+// listVnics returns the ids of vnics of the vnic rules and the dpi enabled vnics
+// in tower, the vnics exempt from redirect have no rules and are not returned.
+func (c *Controller) listVnics(ctx context.Context) (sets.Set[string], sets.Set[string], error) {
+	log := ctrl.LoggerFrom(ctx)
+	rules := &v1alpha1.RuleList{}
+	if err := c.k8scli.List(ctx, rules, k8sclient.InNamespace(constants.VnicRuleNamespace)); err != nil {
+		log.Error(err, "Failed to list vnic rules")
+		return nil, nil, err
+	}
+	exemptions := &v1alpha1.RedirectExemptionList{}
+	if err := c.k8scli.List(ctx, exemptions); err != nil {
+		log.Error(err, "Failed to list redirect exemptions")
+		return nil, nil, err
+	}
+	vnics := datamodel.VMNics{}
+	if err := c.towerCli.List(ctx, datamodel.DPIEnabledVMNicWhere, &vnics); err != nil {
+		log.Error(err, "Failed to list dpi enabled vnics from tower")
+		return nil, nil, err
+	}
+
+	ruleVnics := sets.New[string]()
+	for i := range rules.Items {
+		if vnicID := ruleNameToVnicID(rules.Items[i].Name); vnicID != "" {
+			ruleVnics.Insert(vnicID)
+		}
+	}
+	dpiVnics := sets.New[string]()
+	for i := range vnics {
+		if !exemptsMac(exemptions, vnics[i].MacAddress) {
+			dpiVnics.Insert(vnics[i].GetID())
+		}
+	}
+	return ruleVnics, dpiVnics, nil
+}
+
+func (c *Controller) handle(ctx context.Context, key string) error {
+	if t, id, ok := strings.Cut(key, "/"); ok {
+		return c.handleTowerResource(ctx, datamodel.ResourceType(t), id)
+	}
+	vnicID := key
 	ctx, log := ilog.GetAndSetLogForCtx(ctx, "handlerID", uuid.NewUUID(), "vnicID", vnicID)
 	log.V(4).Info("Handling vnic start")
 	defer log.V(4).Info("Handling vnic end")
@@ -153,43 +306,145 @@ func (c *Controller) handle(ctx context.Context, vnicID string) error {
 		log.Error(err, "Failed to get vnic from tower")
 		return err
 	}
+	allDirects := []v1alpha1.RuleDirect{v1alpha1.Ingress, v1alpha1.Egress, v1alpha1.Both}
 	if !exists {
 		ctx, log := ilog.GetAndSetLogForCtx(ctx, "syncReason", "vnic not exists")
 		log.V(4).Info("Vnic not exists, try to delete related rule")
-		ruleI := vnicIDToRuleName(vnicID, v1alpha1.Ingress)
-		if err := c.deleteRule(ctx, ruleI); err != nil {
-			return err
-		}
-		ruleE := vnicIDToRuleName(vnicID, v1alpha1.Egress)
-		if err := c.deleteRule(ctx, ruleE); err != nil {
-			return err
-		}
-		return nil
+		return c.deleteRules(ctx, vnicID, allDirects)
+	}
+
+	exempt, err := c.isExempt(ctx, vnic.MacAddress)
+	if err != nil {
+		log.Error(err, "Failed to check redirect exemption of vnic")
+		return err
+	}
+	if vnic.DPIEnabled && exempt {
+		ctx, log := ilog.GetAndSetLogForCtx(ctx, "syncReason", "vnic exempt")
+		log.V(4).Info("Vnic mac is exempt from redirect, try to delete related rule")
+		return c.deleteRules(ctx, vnicID, allDirects)
 	}
 
 	if vnic.DPIEnabled {
 		ctx, log := ilog.GetAndSetLogForCtx(ctx, "syncReason", "vnic dpi enabled")
 		log.V(4).Info("Vnic DPI enabled, try to add or update related rule")
-		ruleI := vnicToRule(vnic, v1alpha1.Ingress)
-		if err := c.addOrUpdateRule(ctx, ruleI); err != nil {
+		directs, legacy := vnicRuleDirects(c.bidirectional)
+		for _, d := range directs {
+			if err := c.addOrUpdateRule(ctx, vnicToRule(vnic, d, c.failurePolicy)); err != nil {
+				return err
+			}
+		}
+		// rules of the other layout are cleaned up after the new ones exist,
+		// so the traffic is always matched during migration
+		return c.deleteRules(ctx, vnicID, legacy)
+	}
+
+	ctx, log = ilog.GetAndSetLogForCtx(ctx, "syncReason", "vnic dpi disabled")
+	log.V(4).Info("Vnic DPI disabled, try to delete related rule")
+	return c.deleteRules(ctx, vnicID, allDirects)
+}
+
+// handleTowerResource adds the vnics whose failure policy may be changed by
+// the labels of the vm or the label to the queue.
+func (c *Controller) handleTowerResource(ctx context.Context, t datamodel.ResourceType, id string) error {
+	ctx, log := ilog.GetAndSetLogForCtx(ctx, "handlerID", uuid.NewUUID(), "type", t, "id", id)
+	var vms []datamodel.VM
+	vnics := sets.New[string]()
+	switch t {
+	case datamodel.TypeVM:
+		vm := &datamodel.VM{}
+		exists, err := c.towerCli.Get(ctx, id, vm)
+		if err != nil {
+			log.Error(err, "Failed to get vm from tower")
 			return err
 		}
-		ruleE := vnicToRule(vnic, v1alpha1.Egress)
-		if err := c.addOrUpdateRule(ctx, ruleE); err != nil {
+		// rules of the nics of a deleted vm are deleted on the events of the nics
+		if exists {
+			vms = append(vms, *vm)
+		}
+	case datamodel.TypeLabel:
+		label := &datamodel.Label{}
+		exists, err := c.towerCli.Get(ctx, id, label)
+		if err != nil {
+			log.Error(err, "Failed to get label from tower")
 			return err
 		}
+		if exists && label.Key != constants.TowerFailurePolicyLabel {
+			return nil
+		}
+		if exists {
+			vms = label.VMs
+		}
+		// the vms the label is removed from are not in the label, they are
+		// the vms of rules overriding the default policy
+		overridden, err := c.policyOverriddenVnics(ctx)
+		if err != nil {
+			return err
+		}
+		vnics = vnics.Union(overridden)
+	default:
+		log.Info("Unexpected tower resource type, skip")
 		return nil
 	}
 
-	ctx, log = ilog.GetAndSetLogForCtx(ctx, "syncReason", "vnic dpi disabled")
-	log.V(4).Info("Vnic DPI disabled, try to delete related rule")
-	ruleI := vnicIDToRuleName(vnicID, v1alpha1.Ingress)
-	if err := c.deleteRule(ctx, ruleI); err != nil {
-		return err
+	for i := range vms {
+		for j := range vms[i].VMNics {
+			if nic := &vms[i].VMNics[j]; nic.DPIEnabled {
+				vnics.Insert(nic.GetID())
+			}
+		}
 	}
-	ruleE := vnicIDToRuleName(vnicID, v1alpha1.Egress)
-	if err := c.deleteRule(ctx, ruleE); err != nil {
-		return err
+	for vnicID := range vnics {
+		c.queue.Add(vnicID)
+	}
+	log.V(2).Info("Success to add vnics to queue from tower resource", "vnics", vnics.Len())
+	return nil
+}
+
+// policyOverriddenVnics returns the vnics of rules whose failure policy is set
+// by the label of the vm instead of the controller default.
+func (c *Controller) policyOverriddenVnics(ctx context.Context) (sets.Set[string], error) {
+	rules := &v1alpha1.RuleList{}
+	if err := c.k8scli.List(ctx, rules, k8sclient.InNamespace(constants.VnicRuleNamespace)); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list vnic rules")
+		return nil, err
+	}
+	defaultPolicy := vnicFailurePolicy(&datamodel.VMNic{}, c.failurePolicy)
+	vnics := sets.New[string]()
+	for i := range rules.Items {
+		vnicID := ruleNameToVnicID(rules.Items[i].Name)
+		if vnicID != "" && rules.Items[i].Spec.FailurePolicy != defaultPolicy {
+			vnics.Insert(vnicID)
+		}
+	}
+	return vnics, nil
+}
+
+// isExempt reports whether the traffic of the mac is exempt by any RedirectExemption.
+func (c *Controller) isExempt(ctx context.Context, mac string) (bool, error) {
+	exemptions := &v1alpha1.RedirectExemptionList{}
+	if err := c.k8scli.List(ctx, exemptions); err != nil {
+		return false, err
+	}
+	return exemptsMac(exemptions, mac), nil
+}
+
+func exemptsMac(exemptions *v1alpha1.RedirectExemptionList, mac string) bool {
+	for i := range exemptions.Items {
+		if exemptions.Items[i].Spec.ExemptsMac(mac) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Controller) deleteRules(ctx context.Context, vnicID string, directs []v1alpha1.RuleDirect) error {
+	for _, d := range directs {
+		n := vnicIDToRuleName(vnicID, d)
+		for _, name := range []string{n, replacementRuleName(n)} {
+			if err := c.deleteRule(ctx, name); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -213,27 +468,93 @@ func (c *Controller) deleteRule(ctx context.Context, n string) error {
 	return nil
 }
 
+// addOrUpdateRule creates or updates the rule, the existing rule may be under
+// the name of nRule or its replacement name.
 func (c *Controller) addOrUpdateRule(ctx context.Context, nRule *v1alpha1.Rule) error {
 	k := types.NamespacedName{Namespace: nRule.GetNamespace(), Name: nRule.GetName()}
 	log := ctrl.LoggerFrom(ctx, "ruleKey", k)
-	rule := &v1alpha1.Rule{}
-	if err := c.k8scli.Get(ctx, k, rule); err != nil {
-		if errors.IsNotFound(err) {
-			if err := c.k8scli.Create(ctx, nRule); err != nil {
-				log.Error(err, "Failed to create rule", "rule", *nRule)
-				return err
+	var rules []*v1alpha1.Rule
+	for _, n := range []string{k.Name, replacementRuleName(k.Name)} {
+		rule := &v1alpha1.Rule{}
+		if err := c.k8scli.Get(ctx, types.NamespacedName{Namespace: k.Namespace, Name: n}, rule); err != nil {
+			if errors.IsNotFound(err) {
+				continue
 			}
-			log.Info("Success to create rule", "rule", *nRule)
-			return nil
+			log.Error(err, "Failed to get rule", "name", n)
+			return err
 		}
-		log.Error(err, "Failed to get rule")
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		if err := c.k8scli.Create(ctx, nRule); err != nil {
+			log.Error(err, "Failed to create rule", "rule", *nRule)
+			return err
+		}
+		log.Info("Success to create rule", "rule", *nRule)
+		return nil
+	}
+
+	for i, rule := range rules {
+		if nRule.Spec.ValidateImmutable(&rule.Spec) != nil {
+			continue
+		}
+		if err := c.updateRule(ctx, rule, nRule); err != nil {
+			return err
+		}
+		// the other one is left by an interrupted recreation
+		for j := range rules {
+			if j != i {
+				if err := c.deleteRule(ctx, rules[j].Name); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return c.recreateRule(ctx, rules[0], nRule)
+}
+
+// recreateRule replaces the rule whose direct or macs changed, e.g. the mac of
+// the vnic changed, they can't be updated in place. The replacement is created
+// under the replacement name before the rule is deleted, so the traffic is
+// always redirected.
+func (c *Controller) recreateRule(ctx context.Context, rule, nRule *v1alpha1.Rule) error {
+	log := ctrl.LoggerFrom(ctx, "ruleKey", types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name})
+	if err := nRule.Spec.ValidateImmutable(&rule.Spec); err != nil {
+		log.Info("Rule must be recreated", "reason", err.Error())
+	}
+	nRule = nRule.DeepCopy()
+	nRule.Name = replacementRuleName(rule.Name)
+	// suspend is set by operators on the rule, keep it across reconciles
+	nRule.Spec.Suspend = rule.Spec.Suspend
+	// a stale replacement is left by an interrupted recreation
+	if err := c.deleteRule(ctx, nRule.Name); err != nil {
+		return err
+	}
+	if err := c.k8scli.Create(ctx, nRule); err != nil {
+		log.Error(err, "Failed to create replacement rule", "rule", *nRule)
+		return err
+	}
+	if err := c.deleteRule(ctx, rule.Name); err != nil {
 		return err
 	}
+	log.Info("Success to recreate rule", "rule", *nRule)
+	return nil
+}
 
-	if equality.Semantic.DeepEqual(rule.Spec, nRule.Spec) {
+func (c *Controller) updateRule(ctx context.Context, rule, nRule *v1alpha1.Rule) error {
+	log := ctrl.LoggerFrom(ctx, "ruleKey", types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name})
+	// suspend is set by operators on the rule, keep it across reconciles
+	nRule.Spec.Suspend = rule.Spec.Suspend
+	if equality.Semantic.DeepEqual(rule.Spec, nRule.Spec) && rule.Labels[v1alpha1.LabelManagedBy] == constants.VnicRuleManager {
 		return nil
 	}
 	rule.Spec = nRule.Spec
+	// rules created before the managed-by label are labeled on update
+	if rule.Labels == nil {
+		rule.Labels = make(map[string]string)
+	}
+	rule.Labels[v1alpha1.LabelManagedBy] = constants.VnicRuleManager
 	if err := c.k8scli.Update(ctx, rule); err != nil {
 		log.Error(err, "Failed to update rule", "rule", rule.Spec)
 		return err
@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	gomonkey "github.com/agiledragon/gomonkey/v2"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -50,6 +53,7 @@ type mockK8sClient struct {
 	updateError error
 	deleteError error
 	rules       map[types.NamespacedName]*v1alpha1.Rule
+	exemptions  []v1alpha1.RedirectExemption
 }
 
 func newMockK8sClient() *mockK8sClient {
@@ -76,6 +80,24 @@ func (m *mockK8sClient) Get(ctx context.Context, key k8sclient.ObjectKey, obj k8
 	return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
 }
 
+func (m *mockK8sClient) List(ctx context.Context, list k8sclient.ObjectList, opts ...k8sclient.ListOption) error {
+	switch l := list.(type) {
+	case *v1alpha1.RedirectExemptionList:
+		l.Items = append([]v1alpha1.RedirectExemption(nil), m.exemptions...)
+	case *v1alpha1.RuleList:
+		listOpts := &k8sclient.ListOptions{}
+		listOpts.ApplyOptions(opts)
+		for _, rule := range m.rules {
+			if listOpts.Namespace == "" || rule.Namespace == listOpts.Namespace {
+				l.Items = append(l.Items, *rule.DeepCopy())
+			}
+		}
+	default:
+		return fmt.Errorf("unexpected list type")
+	}
+	return nil
+}
+
 func (m *mockK8sClient) Create(ctx context.Context, obj k8sclient.Object, opts ...k8sclient.CreateOption) error {
 	if m.createError != nil {
 		return m.createError
@@ -300,10 +322,11 @@ var _ = Describe("Vnic Controller", func() {
 			err = c.handle(ctx, "vnic1")
 			Expect(err).NotTo(HaveOccurred())
 
-			// 验证规则已更新
+			// 验证规则已更新, mac 变化的规则以替换名重建
+			Expect(mockClient.rules).To(HaveLen(2))
 			ingressKey := types.NamespacedName{
 				Namespace: constants.VnicRuleNamespace,
-				Name:      vnicIDToRuleName("vnic1", v1alpha1.Ingress),
+				Name:      replacementRuleName(vnicIDToRuleName("vnic1", v1alpha1.Ingress)),
 			}
 			ingressRule := mockClient.rules[ingressKey]
 			Expect(ingressRule.Spec.Match.DstMac).To(Equal("ff:ee:dd:cc:bb:aa"))
@@ -311,7 +334,7 @@ var _ = Describe("Vnic Controller", func() {
 
 			egressKey := types.NamespacedName{
 				Namespace: constants.VnicRuleNamespace,
-				Name:      vnicIDToRuleName("vnic1", v1alpha1.Egress),
+				Name:      replacementRuleName(vnicIDToRuleName("vnic1", v1alpha1.Egress)),
 			}
 			egressRule := mockClient.rules[egressKey]
 			Expect(egressRule.Spec.Match.SrcMac).To(Equal("ff:ee:dd:cc:bb:aa"))
@@ -392,6 +415,224 @@ var _ = Describe("Vnic Controller", func() {
 			Expect(ingressExists).To(BeFalse())
 			Expect(egressExists).To(BeFalse())
 		})
+		It("should delete rules of exempt vnic", func() {
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "Get",
+				func(_ *client.Client, _ context.Context, id string, v datamodel.GqlType) (bool, error) {
+					vnic := v.(*datamodel.VMNic)
+					vnic.ID = "vnic1"
+					vnic.DPIEnabled = true
+					vnic.VM.ID = "vm1"
+					vnic.MacAddress = "aa:bb:cc:dd:ee:ff"
+					return true, nil
+				},
+			)
+			Expect(c.handle(ctx, "vnic1")).To(Succeed())
+			Expect(mockClient.rules).To(HaveLen(2))
+
+			mockClient.exemptions = []v1alpha1.RedirectExemption{{
+				ObjectMeta: metav1.ObjectMeta{Name: "dpi-appliance"},
+				Spec:       v1alpha1.RedirectExemptionSpec{Macs: []string{"aa:bb:cc:00:00:00/ff:ff:ff:00:00:00"}},
+			}}
+			Expect(c.handle(ctx, "vnic1")).To(Succeed())
+			Expect(mockClient.rules).To(BeEmpty())
+		})
+
+		It("should add vnics of rules and tower to queue when exemption changes", func() {
+			rule := createTestRule(vnicIDToRuleName("vnic1", v1alpha1.Egress), string(v1alpha1.Egress), "aa:bb:cc:dd:ee:ff", "", "vm1", "vnic1")
+			mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}] = rule
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "List",
+				func(_ *client.Client, _ context.Context, where string, v datamodel.GqlListType) error {
+					Expect(where).To(Equal(datamodel.DPIEnabledVMNicWhere))
+					vnics := v.(*datamodel.VMNics)
+					*vnics = datamodel.VMNics{{ObjectMeta: datamodel.ObjectMeta{ID: "vnic2"}, DPIEnabled: true}}
+					return nil
+				},
+			)
+			_, err := c.exemptionHandle(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "dpi-appliance"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.queue.Len()).To(Equal(2))
+		})
+
+		It("should add vnics out of sync with tower to queue on resync", func() {
+			for _, r := range []*v1alpha1.Rule{
+				createTestRule(vnicIDToRuleName("vnic1", v1alpha1.Egress), string(v1alpha1.Egress), "aa:bb:cc:dd:ee:01", "", "vm1", "vnic1"),
+				createTestRule(vnicIDToRuleName("vnic1", v1alpha1.Ingress), string(v1alpha1.Ingress), "", "aa:bb:cc:dd:ee:01", "vm1", "vnic1"),
+				createTestRule(vnicIDToRuleName("vnic2", v1alpha1.Both), string(v1alpha1.Both), "", "", "vm1", "vnic2"),
+			} {
+				mockClient.rules[types.NamespacedName{Namespace: r.Namespace, Name: r.Name}] = r
+			}
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "List",
+				func(_ *client.Client, _ context.Context, where string, v datamodel.GqlListType) error {
+					Expect(where).To(Equal(datamodel.DPIEnabledVMNicWhere))
+					vnics := v.(*datamodel.VMNics)
+					*vnics = datamodel.VMNics{
+						{ObjectMeta: datamodel.ObjectMeta{ID: "vnic1"}, DPIEnabled: true},
+						{ObjectMeta: datamodel.ObjectMeta{ID: "vnic3"}, DPIEnabled: true},
+					}
+					return nil
+				},
+			)
+			Expect(c.resync(ctx)).To(Succeed())
+			Expect(c.queue.Len()).To(Equal(2))
+			var queued []string
+			for c.queue.Len() > 0 {
+				item, _ := c.queue.Get()
+				queued = append(queued, item.(string))
+				c.queue.Done(item)
+			}
+			Expect(queued).To(ConsistOf("vnic2", "vnic3"), "vnics in sync are skipped")
+		})
+
+		It("should skip exempt vnics without rules on resync", func() {
+			rule := createTestRule(vnicIDToRuleName("vnic1", v1alpha1.Egress), string(v1alpha1.Egress), "aa:bb:cc:dd:ee:01", "", "vm1", "vnic1")
+			mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}] = rule
+			mockClient.exemptions = []v1alpha1.RedirectExemption{{
+				ObjectMeta: metav1.ObjectMeta{Name: "dpi-appliance"},
+				Spec:       v1alpha1.RedirectExemptionSpec{Macs: []string{"aa:bb:cc:dd:ee:01", "aa:bb:cc:dd:ee:02"}},
+			}}
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "List",
+				func(_ *client.Client, _ context.Context, where string, v datamodel.GqlListType) error {
+					vnics := v.(*datamodel.VMNics)
+					*vnics = datamodel.VMNics{
+						{ObjectMeta: datamodel.ObjectMeta{ID: "vnic1"}, DPIEnabled: true, MacAddress: "aa:bb:cc:dd:ee:01"},
+						{ObjectMeta: datamodel.ObjectMeta{ID: "vnic2"}, DPIEnabled: true, MacAddress: "aa:bb:cc:dd:ee:02"},
+					}
+					return nil
+				},
+			)
+			Expect(c.resync(ctx)).To(Succeed())
+			item, _ := c.queue.Get()
+			Expect(item).To(Equal("vnic1"), "rules of exempt vnics are deleted")
+			c.queue.Done(item)
+			Expect(c.queue.Len()).To(BeZero(), "exempt vnics without rules are in sync")
+		})
+
+		It("should retry the resync on start until it succeeds", func() {
+			defer func(b wait.Backoff) { resyncBackoff = b }(resyncBackoff)
+			resyncBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 2}
+			lists := 0
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "List",
+				func(_ *client.Client, _ context.Context, _ string, v datamodel.GqlListType) error {
+					lists++
+					if lists < 4 {
+						return fmt.Errorf("tower unavailable")
+					}
+					*v.(*datamodel.VMNics) = datamodel.VMNics{{ObjectMeta: datamodel.ObjectMeta{ID: "vnic1"}, DPIEnabled: true}}
+					return nil
+				},
+			)
+			c.resyncLoop(ctx)
+			Expect(lists).To(Equal(4))
+			Expect(c.queue.Len()).To(Equal(1))
+		})
+
+		It("should flip the rule policy when the failure policy label changes", func() {
+			var labels []datamodel.Label
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "Get",
+				func(_ *client.Client, _ context.Context, id string, obj datamodel.GqlType) (bool, error) {
+					nic := datamodel.VMNic{ObjectMeta: datamodel.ObjectMeta{ID: "vnic1"}, DPIEnabled: true, MacAddress: "aa:bb:cc:dd:ee:ff"}
+					switch o := obj.(type) {
+					case *datamodel.VMNic:
+						*o = nic
+						o.VM = datamodel.VM{ID: "vm1", Labels: labels}
+					case *datamodel.VM:
+						*o = datamodel.VM{ID: "vm1", VMNics: []datamodel.VMNic{nic}, Labels: labels}
+					case *datamodel.Label:
+						// the label is removed from the vm
+						*o = datamodel.Label{Key: constants.TowerFailurePolicyLabel, Value: "closed"}
+					}
+					return true, nil
+				},
+			)
+			c.failurePolicy = v1alpha1.FailOpen
+			policies := func() []v1alpha1.FailurePolicy {
+				var ps []v1alpha1.FailurePolicy
+				for _, r := range mockClient.rules {
+					ps = append(ps, r.Spec.FailurePolicy)
+				}
+				return ps
+			}
+			handleQueued := func(expected string) {
+				Expect(c.queue.Len()).To(Equal(1))
+				item, _ := c.queue.Get()
+				Expect(item).To(Equal(expected))
+				Expect(c.handle(ctx, item.(string))).To(Succeed())
+				c.queue.Done(item)
+			}
+
+			labels = []datamodel.Label{{Key: constants.TowerFailurePolicyLabel, Value: "closed"}}
+			Expect(c.handle(ctx, "vnic1")).To(Succeed())
+			Expect(policies()).To(Equal([]v1alpha1.FailurePolicy{v1alpha1.FailClosed, v1alpha1.FailClosed}))
+
+			By("the label of the vm changes")
+			labels = nil
+			revision, action, vmType, vmID := "1", "UPDATED", string(datamodel.TypeVM), "vm1"
+			c.crcHandler(&models.ResourceChangeEvent{Revision: &revision, Action: &action, ResourceType: &vmType, ResourceID: &vmID})
+			handleQueued(towerResourceKey(datamodel.TypeVM, "vm1"))
+			handleQueued("vnic1")
+			Expect(policies()).To(Equal([]v1alpha1.FailurePolicy{v1alpha1.FailOpen, v1alpha1.FailOpen}))
+
+			By("the label is removed from the vm")
+			labels = []datamodel.Label{{Key: constants.TowerFailurePolicyLabel, Value: "closed"}}
+			Expect(c.handle(ctx, "vnic1")).To(Succeed())
+			labels = nil
+			Expect(c.handle(ctx, towerResourceKey(datamodel.TypeLabel, "label1"))).To(Succeed())
+			handleQueued("vnic1")
+			Expect(policies()).To(Equal([]v1alpha1.FailurePolicy{v1alpha1.FailOpen, v1alpha1.FailOpen}))
+		})
+
+		It("should migrate legacy rules to a single bidirectional rule", func() {
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "Get",
+				func(_ *client.Client, _ context.Context, id string, v datamodel.GqlType) (bool, error) {
+					vnic := v.(*datamodel.VMNic)
+					vnic.ID = "vnic1"
+					vnic.DPIEnabled = true
+					vnic.VM.ID = "vm1"
+					vnic.MacAddress = "aa:bb:cc:dd:ee:ff"
+					return true, nil
+				},
+			)
+			err := c.handle(ctx, "vnic1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockClient.rules).To(HaveLen(2))
+
+			c.bidirectional = true
+			err = c.handle(ctx, "vnic1")
+			Expect(err).NotTo(HaveOccurred())
+			bothKey := types.NamespacedName{
+				Namespace: constants.VnicRuleNamespace,
+				Name:      vnicIDToRuleName("vnic1", v1alpha1.Both),
+			}
+			Expect(mockClient.rules).To(HaveLen(1))
+			Expect(mockClient.rules).To(HaveKey(bothKey))
+			bothRule := mockClient.rules[bothKey]
+			Expect(bothRule.Spec.Direct).To(Equal(v1alpha1.Both))
+			Expect(bothRule.Spec.Match.Mac).To(Equal("aa:bb:cc:dd:ee:ff"))
+			Expect(bothRule.Spec.Match.SrcMac).To(BeEmpty())
+			Expect(bothRule.Spec.Match.DstMac).To(BeEmpty())
+
+			// roll back to the legacy pair
+			c.bidirectional = false
+			err = c.handle(ctx, "vnic1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockClient.rules).To(HaveLen(2))
+			Expect(mockClient.rules).NotTo(HaveKey(bothKey))
+		})
+
+		It("should delete bidirectional rule when vnic not found in tower", func() {
+			c.bidirectional = true
+			bothName := vnicIDToRuleName("vnic1", v1alpha1.Both)
+			mockClient.rules[types.NamespacedName{Namespace: constants.VnicRuleNamespace, Name: bothName}] = createTestRule(bothName, string(v1alpha1.Both), "", "", "vm1", "vnic1")
+
+			patches = gomonkey.ApplyMethod(reflect.TypeOf(towerCli), "Get",
+				func(_ *client.Client, _ context.Context, id string, vnic datamodel.GqlType) (bool, error) {
+					return false, nil
+				},
+			)
+			err := c.handle(ctx, "vnic1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockClient.rules).To(BeEmpty())
+		})
 	})
 	Context("ruleHandle function", func() {
 		BeforeEach(func() {
@@ -506,11 +747,11 @@ var _ = Describe("Vnic Controller", func() {
 
 			// 修改规则
 			newRule := rule.DeepCopy()
-			newRule.Spec.Match.DstMac = "ff:ee:dd:cc:bb:aa"
+			newRule.Spec.Option.TowerVM = "vm2"
 
 			err := c.addOrUpdateRule(ctx, newRule)
 			Expect(err).NotTo(HaveOccurred())
-			Expect(mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}].Spec.Match.DstMac).To(Equal("ff:ee:dd:cc:bb:aa"))
+			Expect(mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}].Spec.Option.TowerVM).To(Equal("vm2"))
 		})
 
 		It("should not update rule when spec is identical", func() {
@@ -523,6 +764,34 @@ var _ = Describe("Vnic Controller", func() {
 			// 规则应该保持不变
 		})
 
+		It("should keep suspend of existing rule", func() {
+			rule := createTestRule("existing-rule", string(v1alpha1.Ingress), "", "aa:bb:cc:dd:ee:ff", "vm1", "vnic1")
+			rule.Spec.Suspend = true
+			mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}] = rule.DeepCopy()
+
+			newRule := rule.DeepCopy()
+			newRule.Spec.Suspend = false
+			newRule.Spec.Option.TowerVM = "vm2"
+
+			err := c.addOrUpdateRule(ctx, newRule)
+			Expect(err).NotTo(HaveOccurred())
+			updated := mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}]
+			Expect(updated.Spec.Suspend).To(BeTrue())
+			Expect(updated.Spec.Option.TowerVM).To(Equal("vm2"))
+		})
+
+		It("should label existing rule without managed-by label", func() {
+			rule := createTestRule("existing-rule", string(v1alpha1.Ingress), "", "aa:bb:cc:dd:ee:ff", "vm1", "vnic1")
+			mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}] = rule.DeepCopy()
+
+			newRule := rule.DeepCopy()
+			newRule.Labels = map[string]string{v1alpha1.LabelManagedBy: constants.VnicRuleManager}
+			err := c.addOrUpdateRule(ctx, newRule)
+			Expect(err).NotTo(HaveOccurred())
+			updated := mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}]
+			Expect(updated.Labels).To(HaveKeyWithValue(v1alpha1.LabelManagedBy, constants.VnicRuleManager))
+		})
+
 		It("should return error on get failure", func() {
 			rule := createTestRule("some-rule", string(v1alpha1.Ingress), "", "aa:bb:cc:dd:ee:ff", "vm1", "vnic1")
 			mockClient.getError = fmt.Errorf("get error")
@@ -538,12 +807,61 @@ var _ = Describe("Vnic Controller", func() {
 
 			// 修改规则并设置更新错误
 			newRule := rule.DeepCopy()
-			newRule.Spec.Match.DstMac = "ff:ee:dd:cc:bb:aa"
+			newRule.Spec.Option.TowerVM = "vm2"
 			mockClient.updateError = fmt.Errorf("update error")
 
 			err := c.addOrUpdateRule(ctx, newRule)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("update error"))
 		})
+
+		It("should create the replacement before deleting the rule when mac changes", func() {
+			rule := createTestRule("existing-rule", string(v1alpha1.Ingress), "", "aa:bb:cc:dd:ee:ff", "vm1", "vnic1")
+			rule.Spec.Suspend = true
+			key := types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}
+			replacementKey := types.NamespacedName{Namespace: rule.Namespace, Name: "existing-rule-r"}
+			mockClient.rules[key] = rule.DeepCopy()
+
+			newRule := rule.DeepCopy()
+			newRule.Spec.Suspend = false
+			newRule.Spec.Match.DstMac = "ff:ee:dd:cc:bb:aa"
+			mockClient.updateError = fmt.Errorf("update error")
+			mockClient.createError = fmt.Errorf("create error")
+			err := c.addOrUpdateRule(ctx, newRule)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("create error"), "the direct and macs of rule are immutable")
+			Expect(mockClient.rules).To(HaveKey(key), "the rule is kept until the replacement is created")
+
+			mockClient.createError = nil
+			err = c.addOrUpdateRule(ctx, newRule)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockClient.rules).NotTo(HaveKey(key))
+			Expect(mockClient.rules).To(HaveKey(replacementKey))
+			Expect(mockClient.rules[replacementKey].Spec.Match.DstMac).To(Equal("ff:ee:dd:cc:bb:aa"))
+			Expect(mockClient.rules[replacementKey].Spec.Suspend).To(BeTrue())
+
+			// the replacement is found and replaced back under the original name
+			mockClient.updateError = nil
+			err = c.addOrUpdateRule(ctx, rule.DeepCopy())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockClient.rules).NotTo(HaveKey(replacementKey))
+			Expect(mockClient.rules[key].Spec.Match.DstMac).To(Equal("aa:bb:cc:dd:ee:ff"))
+		})
+
+		It("should delete the rule left by an interrupted recreation", func() {
+			rule := createTestRule("existing-rule", string(v1alpha1.Ingress), "", "aa:bb:cc:dd:ee:ff", "vm1", "vnic1")
+			replacement := rule.DeepCopy()
+			replacement.Name = "existing-rule-r"
+			replacement.Spec.Match.DstMac = "ff:ee:dd:cc:bb:aa"
+			mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: rule.Name}] = rule.DeepCopy()
+			mockClient.rules[types.NamespacedName{Namespace: rule.Namespace, Name: replacement.Name}] = replacement.DeepCopy()
+
+			newRule := replacement.DeepCopy()
+			newRule.Name = rule.Name
+			err := c.addOrUpdateRule(ctx, newRule)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockClient.rules).To(HaveLen(1))
+			Expect(mockClient.rules).To(HaveKey(types.NamespacedName{Namespace: rule.Namespace, Name: replacement.Name}))
+		})
 	})
 })
@@ -11,29 +11,61 @@ import (
 	"github.com/everoute/trafficredirect/pkg/tower/datamodel"
 )
 
+// replacementSuffix is the suffix of the name of the replacement of a vnic rule,
+// the rule is replaced when its direct or macs change, see recreateRule.
+const replacementSuffix = "r"
+
 func vnicIDToRuleName(vnicID string, d v1alpha1.RuleDirect) string {
 	return fmt.Sprintf("%s-%s-%s", constants.VnicRulePrefix, vnicID, d)
 }
 
+// replacementRuleName returns the name the rule is recreated under, the rule
+// alternates between the name with and without the replacement suffix.
+func replacementRuleName(n string) string {
+	if trimmed, ok := strings.CutSuffix(n, "-"+replacementSuffix); ok {
+		return trimmed
+	}
+	return n + "-" + replacementSuffix
+}
+
 func ruleNameToVnicID(n string) string {
 	parts := strings.Split(n, "-")
+	if len(parts) == 4 && parts[3] == replacementSuffix {
+		parts = parts[:3]
+	}
 	if len(parts) != 3 || parts[0] != constants.VnicRulePrefix {
 		return ""
 	}
 
-	if parts[2] != string(v1alpha1.Ingress) && parts[2] != string(v1alpha1.Egress) {
+	switch v1alpha1.RuleDirect(parts[2]) {
+	case v1alpha1.Ingress, v1alpha1.Egress, v1alpha1.Both:
+	default:
 		return ""
 	}
 
 	return parts[1]
 }
 
-func vnicToRule(vnic *datamodel.VMNic, d v1alpha1.RuleDirect) *v1alpha1.Rule {
+// vnicRuleDirects returns the directs of rules to create for a vnic, and the
+// directs of rules to clean up.
+func vnicRuleDirects(bidirectional bool) ([]v1alpha1.RuleDirect, []v1alpha1.RuleDirect) {
+	if bidirectional {
+		return []v1alpha1.RuleDirect{v1alpha1.Both}, []v1alpha1.RuleDirect{v1alpha1.Ingress, v1alpha1.Egress}
+	}
+	return []v1alpha1.RuleDirect{v1alpha1.Ingress, v1alpha1.Egress}, []v1alpha1.RuleDirect{v1alpha1.Both}
+}
+
+func vnicToRule(vnic *datamodel.VMNic, d v1alpha1.RuleDirect, defaultPolicy v1alpha1.FailurePolicy) *v1alpha1.Rule {
 	name := vnicIDToRuleName(vnic.GetID(), d)
 	rule := &v1alpha1.Rule{
-		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: constants.VnicRuleNamespace},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: constants.VnicRuleNamespace,
+			Labels:    map[string]string{v1alpha1.LabelManagedBy: constants.VnicRuleManager},
+		},
 		Spec: v1alpha1.RuleSpec{
-			Direct: d,
+			Direct:        d,
+			FailurePolicy: vnicFailurePolicy(vnic, defaultPolicy),
 			Option: &v1alpha1.Option{
 				TowerVM: vnic.VM.ID,
 			},
@@ -45,5 +77,26 @@ func vnicToRule(vnic *datamodel.VMNic, d v1alpha1.RuleDirect) *v1alpha1.Rule {
 	if d == v1alpha1.Ingress {
 		rule.Spec.Match.DstMac = vnic.MacAddress
 	}
+	if d == v1alpha1.Both {
+		rule.Spec.Match.Mac = vnic.MacAddress
+	}
 	return rule
 }
+
+// vnicFailurePolicy returns the failure policy of the vnic rules, the label of
+// the tower vm overrides the controller default. Invalid label values are ignored.
+func vnicFailurePolicy(vnic *datamodel.VMNic, defaultPolicy v1alpha1.FailurePolicy) v1alpha1.FailurePolicy {
+	for _, l := range vnic.VM.Labels {
+		if l.Key != constants.TowerFailurePolicyLabel {
+			continue
+		}
+		switch p := v1alpha1.FailurePolicy(l.Value); p {
+		case v1alpha1.FailOpen, v1alpha1.FailClosed:
+			return p
+		}
+	}
+	if defaultPolicy == v1alpha1.FailClosed {
+		return defaultPolicy
+	}
+	return v1alpha1.FailOpen
+}
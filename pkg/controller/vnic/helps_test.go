@@ -28,6 +28,14 @@ var _ = Describe("Vnic Helper Functions", func() {
 		})
 	})
 
+	Describe("replacementRuleName", func() {
+		It("should alternate between the names with and without the suffix", func() {
+			n := vnicIDToRuleName("vnic1", v1alpha1.Both)
+			Expect(replacementRuleName(n)).To(Equal(n + "-r"))
+			Expect(replacementRuleName(replacementRuleName(n))).To(Equal(n))
+		})
+	})
+
 	Describe("ruleNameToVnicID", func() {
 		It("should return vnicID for valid rule name", func() {
 			vnicID := ruleNameToVnicID(constants.VnicRulePrefix + "-vnic123-ingress")
@@ -35,6 +43,12 @@ var _ = Describe("Vnic Helper Functions", func() {
 
 			vnicID = ruleNameToVnicID(constants.VnicRulePrefix + "-vnic456-egress")
 			Expect(vnicID).To(Equal("vnic456"))
+
+			vnicID = ruleNameToVnicID(constants.VnicRulePrefix + "-vnic789-both")
+			Expect(vnicID).To(Equal("vnic789"))
+
+			vnicID = ruleNameToVnicID(constants.VnicRulePrefix + "-vnic789-both-r")
+			Expect(vnicID).To(Equal("vnic789"))
 		})
 
 		It("should return empty string for invalid rule name", func() {
@@ -59,7 +73,7 @@ var _ = Describe("Vnic Helper Functions", func() {
 		})
 
 		It("should generate ingress rule correctly", func() {
-			rule := vnicToRule(testVnic, v1alpha1.Ingress)
+			rule := vnicToRule(testVnic, v1alpha1.Ingress, v1alpha1.FailOpen)
 			Expect(rule.Name).To(Equal(constants.VnicRulePrefix + "-vnic-1-ingress"))
 			Expect(rule.Namespace).To(Equal(constants.VnicRuleNamespace))
 			Expect(rule.Spec.Direct).To(Equal(v1alpha1.Ingress))
@@ -69,7 +83,7 @@ var _ = Describe("Vnic Helper Functions", func() {
 		})
 
 		It("should generate egress rule correctly", func() {
-			rule := vnicToRule(testVnic, v1alpha1.Egress)
+			rule := vnicToRule(testVnic, v1alpha1.Egress, v1alpha1.FailOpen)
 			Expect(rule.Name).To(Equal(constants.VnicRulePrefix + "-vnic-1-egress"))
 			Expect(rule.Namespace).To(Equal(constants.VnicRuleNamespace))
 			Expect(rule.Spec.Direct).To(Equal(v1alpha1.Egress))
@@ -77,5 +91,25 @@ var _ = Describe("Vnic Helper Functions", func() {
 			Expect(rule.Spec.Match.DstMac).To(BeEmpty())
 			Expect(rule.Spec.Option.TowerVM).To(Equal("vm-123"))
 		})
+
+		It("should generate bidirectional rule correctly", func() {
+			rule := vnicToRule(testVnic, v1alpha1.Both, v1alpha1.FailOpen)
+			Expect(rule.Name).To(Equal(constants.VnicRulePrefix + "-vnic-1-both"))
+			Expect(rule.Spec.Direct).To(Equal(v1alpha1.Both))
+			Expect(rule.Spec.Match.Mac).To(Equal("aa:bb:cc:dd:ee:ff"))
+			Expect(rule.Spec.Match.SrcMac).To(BeEmpty())
+			Expect(rule.Spec.Match.DstMac).To(BeEmpty())
+			Expect(rule.Validate()).To(Succeed())
+		})
+
+		It("should set failure policy by the vm label or the default", func() {
+			Expect(vnicToRule(testVnic, v1alpha1.Both, v1alpha1.FailClosed).Spec.FailurePolicy).To(Equal(v1alpha1.FailClosed))
+
+			testVnic.VM.Labels = []datamodel.Label{{Key: constants.TowerFailurePolicyLabel, Value: "open"}}
+			Expect(vnicToRule(testVnic, v1alpha1.Both, v1alpha1.FailClosed).Spec.FailurePolicy).To(Equal(v1alpha1.FailOpen))
+
+			testVnic.VM.Labels[0].Value = "unknown"
+			Expect(vnicToRule(testVnic, v1alpha1.Both, "").Spec.FailurePolicy).To(Equal(v1alpha1.FailOpen))
+		})
 	})
 })
@@ -36,12 +36,18 @@ func NewClient() *Client {
 }
 
 func (c *Client) Get(ctx context.Context, id string, obj datamodel.GqlType) (bool, error) {
-	return c.get(ctx, id, obj, true)
+	return c.query(ctx, obj.GqlGetStr(id), obj.TypeName(), obj, true)
 }
 
-func (c *Client) get(ctx context.Context, id string, obj datamodel.GqlType, authRetry bool) (bool, error) {
+// List queries the objects matching the gql filter where into the list obj.
+func (c *Client) List(ctx context.Context, where string, obj datamodel.GqlListType) error {
+	_, err := c.query(ctx, obj.GqlListStr(where), obj.TypeName(), obj, true)
+	return err
+}
+
+func (c *Client) query(ctx context.Context, query, typeName string, obj interface{}, authRetry bool) (bool, error) {
 	log := ctrl.LoggerFrom(ctx)
-	req := &graphcclient.Request{Query: obj.GqlGetStr(id)}
+	req := &graphcclient.Request{Query: query}
 	resp, err := c.Cli.Query(req)
 	if err != nil {
 		log.Error(err, "query gql error")
@@ -57,7 +63,7 @@ func (c *Client) get(ctx context.Context, id string, obj datamodel.GqlType, auth
 				return false, err
 			}
 			log.Info("tower client re-auth success")
-			return c.get(ctx, id, obj, false)
+			return c.query(ctx, query, typeName, obj, false)
 		}
 		return false, err
 	}
@@ -67,15 +73,15 @@ func (c *Client) get(ctx context.Context, id string, obj datamodel.GqlType, auth
 		log.Error(err, "unmarshal gql resp data error", "data", string(resp.Data))
 		return false, err
 	}
-	if _, ok := data[obj.TypeName()]; !ok {
-		log.Error(err, "gql resp data missing object", "object", obj.TypeName(), "data", data)
-		return false, fmt.Errorf("gql resp data missing object %s", obj.TypeName())
+	if _, ok := data[typeName]; !ok {
+		log.Error(err, "gql resp data missing object", "object", typeName, "data", data)
+		return false, fmt.Errorf("gql resp data missing object %s", typeName)
 	}
-	if string(data[obj.TypeName()]) == "null" {
+	if string(data[typeName]) == "null" {
 		return false, nil
 	}
-	if err := json.Unmarshal(data[obj.TypeName()], obj); err != nil {
-		log.Error(err, "unmarshal gql resp object error", "object", obj.TypeName(), "data", data)
+	if err := json.Unmarshal(data[typeName], obj); err != nil {
+		log.Error(err, "unmarshal gql resp object error", "object", typeName, "data", data)
 		return false, err
 	}
 	return true, nil
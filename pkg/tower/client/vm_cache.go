@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/everoute/trafficredirect/pkg/tower/datamodel"
+)
+
+// VMCache caches the nic macs of tower vms for a short ttl, so the webhook
+// doesn't query tower for every admission. Vms not found are cached too,
+// errors are not.
+type VMCache struct {
+	get   func(ctx context.Context, id string, obj datamodel.GqlType) (bool, error)
+	ttl   time.Duration
+	clock clock.PassiveClock
+
+	lock    sync.Mutex
+	entries map[string]vmCacheEntry
+}
+
+type vmCacheEntry struct {
+	macs    []string
+	exists  bool
+	expires time.Time
+}
+
+func NewVMCache(cli *Client, ttl time.Duration) *VMCache {
+	return &VMCache{
+		get:     cli.Get,
+		ttl:     ttl,
+		clock:   clock.RealClock{},
+		entries: make(map[string]vmCacheEntry),
+	}
+}
+
+// VMMacs returns the lower case nic macs of the tower vm, exists is false when
+// the vm isn't found in tower.
+func (c *VMCache) VMMacs(ctx context.Context, id string) ([]string, bool, error) {
+	now := c.clock.Now()
+	c.lock.Lock()
+	e, ok := c.entries[id]
+	c.lock.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.macs, e.exists, nil
+	}
+
+	vm := &datamodel.VM{}
+	exists, err := c.get(ctx, id, vm)
+	if err != nil {
+		return nil, false, err
+	}
+	e = vmCacheEntry{exists: exists, expires: now.Add(c.ttl)}
+	for _, nic := range vm.VMNics {
+		e.macs = append(e.macs, strings.ToLower(nic.MacAddress))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k, old := range c.entries {
+		if !now.Before(old.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[id] = e
+	return e.macs, e.exists, nil
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/everoute/trafficredirect/pkg/tower/datamodel"
+)
+
+func TestVMCache(t *testing.T) {
+	now := time.Now()
+	clk := testingclock.NewFakePassiveClock(now)
+	queries := 0
+	var queryErr error
+	c := &VMCache{
+		get: func(_ context.Context, id string, obj datamodel.GqlType) (bool, error) {
+			queries++
+			if queryErr != nil {
+				return false, queryErr
+			}
+			if id != "vm1" {
+				return false, nil
+			}
+			obj.(*datamodel.VM).VMNics = []datamodel.VMNic{{MacAddress: "00:AA:22:33:44:55"}}
+			return true, nil
+		},
+		ttl:     10 * time.Second,
+		clock:   clk,
+		entries: make(map[string]vmCacheEntry),
+	}
+	ctx := context.Background()
+
+	macs, exists, err := c.VMMacs(ctx, "vm1")
+	if err != nil || !exists || !reflect.DeepEqual(macs, []string{"00:aa:22:33:44:55"}) {
+		t.Fatalf("VMMacs(vm1) = %v, %v, %v", macs, exists, err)
+	}
+	if _, exists, _ := c.VMMacs(ctx, "vm2"); exists {
+		t.Fatalf("vm2 should not exist")
+	}
+	c.VMMacs(ctx, "vm1")
+	c.VMMacs(ctx, "vm2")
+	if queries != 2 {
+		t.Fatalf("queries = %d, want 2 within the ttl", queries)
+	}
+
+	clk.SetTime(now.Add(10 * time.Second))
+	queryErr = fmt.Errorf("tower unavailable")
+	if _, _, err := c.VMMacs(ctx, "vm1"); err == nil {
+		t.Fatalf("want error of expired entry")
+	}
+	if len(c.entries) != 2 {
+		t.Fatalf("errors should not be cached")
+	}
+	queryErr = nil
+	if _, exists, _ := c.VMMacs(ctx, "vm1"); !exists || queries != 4 {
+		t.Fatalf("VMMacs(vm1) exists = %v, queries = %d, want requery after ttl", exists, queries)
+	}
+	if _, ok := c.entries["vm2"]; ok {
+		t.Fatalf("expired entry of vm2 should be pruned")
+	}
+}
@@ -0,0 +1,47 @@
+package datamodel
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	LabelGqlTypeName  = "label"
+	LabelsGqlTypeName = "labels"
+	LabelGqlFields    = "{id,key,value,vms{id,vm_nics{id,mac_address,dpi_enabled}}}"
+)
+
+type Label struct {
+	ObjectMeta
+
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	VMs   []VM   `json:"vms,omitempty"`
+}
+
+func (r Label) GqlGetStr(id string) string {
+	return fmt.Sprintf("query {%s(where:{id:\"%s\"}) %s}", LabelGqlTypeName, id, LabelGqlFields)
+}
+
+func (r Label) TypeName() string {
+	return LabelGqlTypeName
+}
+
+type Labels []Label
+
+func (r Labels) GqlListStr(where string) string {
+	return fmt.Sprintf("query {%s(where:%s) %s}", LabelsGqlTypeName, where, LabelGqlFields)
+}
+
+func (r Labels) TypeName() string {
+	return LabelsGqlTypeName
+}
+
+// LabelWhere returns the gql filter of labels by key and value, labels of any
+// value match when the value is empty.
+func LabelWhere(key, value string) string {
+	if value == "" {
+		return fmt.Sprintf("{key:%s}", strconv.Quote(key))
+	}
+	return fmt.Sprintf("{key:%s,value:%s}", strconv.Quote(key), strconv.Quote(value))
+}
@@ -4,9 +4,16 @@ type ResourceType string
 
 const (
 	TypeVMNic ResourceType = "VmNic"
+	TypeVM    ResourceType = "Vm"
+	TypeLabel ResourceType = "Label"
 )
 
 type GqlType interface {
 	GqlGetStr(id string) string
 	TypeName() string
 }
+
+type GqlListType interface {
+	GqlListStr(where string) string
+	TypeName() string
+}
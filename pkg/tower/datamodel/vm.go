@@ -0,0 +1,25 @@
+package datamodel
+
+import (
+	"fmt"
+)
+
+const (
+	VMGqlTypeName = "vm"
+	VMGqlFields   = "{id,vm_nics{id,mac_address,dpi_enabled},labels{id,key,value}}"
+)
+
+type VM struct {
+	ID string `json:"id"`
+
+	VMNics []VMNic `json:"vm_nics,omitempty"`
+	Labels []Label `json:"labels,omitempty"`
+}
+
+func (r VM) GqlGetStr(id string) string {
+	return fmt.Sprintf("query {%s(where:{id:\"%s\"}) %s}", VMGqlTypeName, id, VMGqlFields)
+}
+
+func (r VM) TypeName() string {
+	return VMGqlTypeName
+}
@@ -6,7 +6,7 @@ import (
 
 const (
 	VMNicGqlTypeName = "vmNic"
-	VMNicGqlFields   = "{id,dpi_enabled,mac_address,vm{id}}"
+	VMNicGqlFields   = "{id,dpi_enabled,mac_address,vm{id,labels{id,key,value}}}"
 )
 
 type VMNic struct {
@@ -17,10 +17,6 @@ type VMNic struct {
 	VM         VM     `json:"vm,omitempty"`
 }
 
-type VM struct {
-	ID string `json:"id"`
-}
-
 func (r VMNic) GqlGetStr(id string) string {
 	return fmt.Sprintf("query {%s(where:{id:\"%s\"}) %s}", VMNicGqlTypeName, id, VMNicGqlFields)
 }
@@ -28,3 +24,19 @@ func (r VMNic) GqlGetStr(id string) string {
 func (r VMNic) TypeName() string {
 	return VMNicGqlTypeName
 }
+
+const (
+	VMNicsGqlTypeName = "vmNics"
+	// DPIEnabledVMNicWhere is the gql filter of vnics with dpi enabled.
+	DPIEnabledVMNicWhere = "{dpi_enabled:true}"
+)
+
+type VMNics []VMNic
+
+func (r VMNics) GqlListStr(where string) string {
+	return fmt.Sprintf("query {%s(where:%s) %s}", VMNicsGqlTypeName, where, VMNicGqlFields)
+}
+
+func (r VMNics) TypeName() string {
+	return VMNicsGqlTypeName
+}